@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"team/pkg"
+)
+
+// remote talks to TeamSystem.AdminHandler of a running service
+type remote struct {
+	base   string
+	client *http.Client
+}
+
+func newRemote(base string) *remote {
+	return &remote{base: strings.TrimSuffix(base, "/"), client: http.DefaultClient}
+}
+
+func (r *remote) do(method, path string, out any) error {
+	req, err := http.NewRequest(method, r.base+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, body.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *remote) Teams() ([]pkg.TeamSnapshot, error) {
+	var teams []pkg.TeamSnapshot
+	return teams, r.do("GET", "/teams", &teams)
+}
+
+func (r *remote) Team(teamID uint64) (pkg.TeamSnapshot, error) {
+	var team pkg.TeamSnapshot
+	return team, r.do("GET", fmt.Sprintf("/teams/%d", teamID), &team)
+}
+
+func (r *remote) Player(guid uint64) (pkg.PlayerTeam, error) {
+	var player pkg.PlayerTeam
+	return player, r.do("GET", fmt.Sprintf("/players/%d", guid), &player)
+}
+
+func (r *remote) Disband(teamID uint64) (pkg.AdminResult, error) {
+	var result pkg.AdminResult
+	return result, r.do("POST", fmt.Sprintf("/teams/%d/disband", teamID), &result)
+}
+
+func (r *remote) Leader(teamID, guid uint64) (pkg.AdminResult, error) {
+	var result pkg.AdminResult
+	return result, r.do("POST", fmt.Sprintf("/teams/%d/leader/%d", teamID, guid), &result)
+}
+
+func (r *remote) Stale() (pkg.GuidVector, error) {
+	var stale pkg.GuidVector
+	return stale, r.do("GET", "/index/stale", &stale)
+}
+
+func (r *remote) Unindex(guid uint64) (pkg.AdminResult, error) {
+	var result pkg.AdminResult
+	return result, r.do("DELETE", fmt.Sprintf("/index/%d", guid), &result)
+}
+
+// local works on a TeamSystem restored from a snapshot file and saves changes back to it
+type local struct {
+	path string
+	ts   *pkg.TeamSystem
+}
+
+func loadSnapshot(path string) (*local, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot pkg.SystemSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &local{path: path, ts: pkg.RestoreTeamSystem(snapshot)}, nil
+}
+
+func (l *local) save(result uint32) (pkg.AdminResult, error) {
+	if result != 0 { // Only successful changes are saved
+		return pkg.AdminResult{Result: result}, nil
+	}
+	data, err := json.MarshalIndent(l.ts.Snapshot(), "", "  ")
+	if err != nil {
+		return pkg.AdminResult{}, err
+	}
+	return pkg.AdminResult{Result: result}, os.WriteFile(l.path, data, 0o644)
+}
+
+func (l *local) Teams() ([]pkg.TeamSnapshot, error) {
+	return l.ts.Snapshot().Teams, nil
+}
+
+func (l *local) Team(teamID uint64) (pkg.TeamSnapshot, error) {
+	team, ok := l.ts.TeamSnapshot(teamID)
+	if !ok {
+		return team, fmt.Errorf("team %d not found", teamID)
+	}
+	return team, nil
+}
+
+func (l *local) Player(guid uint64) (pkg.PlayerTeam, error) {
+	return pkg.PlayerTeam{Guid: guid, TeamID: l.ts.GetTeamID(guid)}, nil
+}
+
+func (l *local) Disband(teamID uint64) (pkg.AdminResult, error) {
+	return l.save(l.ts.DisbandedTeamNoLeader(teamID))
+}
+
+func (l *local) Leader(teamID, guid uint64) (pkg.AdminResult, error) {
+	return l.save(l.ts.AppointLeader(teamID, l.ts.GetLeaderIDByTeamID(teamID), guid))
+}
+
+// Stale is always empty, a snapshot only indexes the members of its teams
+func (l *local) Stale() (pkg.GuidVector, error) {
+	return l.ts.StalePlayerIndex(), nil
+}
+
+func (l *local) Unindex(guid uint64) (pkg.AdminResult, error) {
+	return pkg.AdminResult{Removed: l.ts.RemovePlayerIndex(guid)}, nil
+}
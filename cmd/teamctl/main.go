@@ -0,0 +1,184 @@
+// Command teamctl inspects and repairs teams, either on a running service
+// exposing TeamSystem.AdminHandler or in a snapshot file.
+//
+// Usage:
+//
+//	teamctl [-addr URL | -snapshot FILE] [-json] command [args]
+//
+// Commands:
+//
+//	teams               list teams
+//	team ID             show members and applicants of a team
+//	player GUID         show the team of a player
+//	disband ID          force-disband a team
+//	leader ID GUID      force-transfer leadership
+//	stale               list stuck player-index entries
+//	unindex GUID        remove a stuck player-index entry
+//
+// Changes made on a snapshot file are written back to it.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"team/pkg"
+)
+
+// backend is the service or snapshot file teamctl works on
+type backend interface {
+	Teams() ([]pkg.TeamSnapshot, error)
+	Team(teamID uint64) (pkg.TeamSnapshot, error)
+	Player(guid uint64) (pkg.PlayerTeam, error)
+	Disband(teamID uint64) (pkg.AdminResult, error)
+	Leader(teamID, guid uint64) (pkg.AdminResult, error)
+	Stale() (pkg.GuidVector, error)
+	Unindex(guid uint64) (pkg.AdminResult, error)
+}
+
+func main() {
+	addr := flag.String("addr", "", "base URL of the admin endpoint of a running service")
+	snapshot := flag.String("snapshot", "", "snapshot file to load instead of a running service")
+	asJSON := flag.Bool("json", false, "print JSON instead of tables")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: teamctl [-addr URL | -snapshot FILE] [-json] teams|team|player|disband|leader|stale|unindex [args]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || (*addr == "") == (*snapshot == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var b backend
+	if *addr != "" {
+		b = newRemote(*addr)
+	} else {
+		local, err := loadSnapshot(*snapshot)
+		if err != nil {
+			fail(err)
+		}
+		b = local
+	}
+
+	if err := run(b, flag.Args(), *asJSON, os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "teamctl:", err)
+	os.Exit(1)
+}
+
+func run(b backend, args []string, asJSON bool, w io.Writer) error {
+	command, ids, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	var body any
+	switch command {
+	case "teams":
+		body, err = b.Teams()
+	case "team":
+		body, err = b.Team(ids[0])
+	case "player":
+		body, err = b.Player(ids[0])
+	case "disband":
+		body, err = b.Disband(ids[0])
+	case "leader":
+		body, err = b.Leader(ids[0], ids[1])
+	case "stale":
+		body, err = b.Stale()
+	case "unindex":
+		body, err = b.Unindex(ids[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(body)
+	}
+	return printTable(w, body)
+}
+
+var commandArgs = map[string]int{
+	"teams": 0, "team": 1, "player": 1, "disband": 1, "leader": 2, "stale": 0, "unindex": 1,
+}
+
+func parseArgs(args []string) (string, []uint64, error) {
+	command := args[0]
+	count, ok := commandArgs[command]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown command %q", command)
+	}
+	if len(args)-1 != count {
+		return "", nil, fmt.Errorf("%s takes %d arguments", command, count)
+	}
+	ids := make([]uint64, 0, count)
+	for _, arg := range args[1:] {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: invalid id %q", command, arg)
+		}
+		ids = append(ids, id)
+	}
+	return command, ids, nil
+}
+
+func printTable(w io.Writer, body any) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch body := body.(type) {
+	case []pkg.TeamSnapshot:
+		fmt.Fprintln(tw, "ID\tLEADER\tMEMBERS\tAPPLICANTS\tRAID\tVERSION")
+		for _, team := range body {
+			fmt.Fprintf(tw, "%d\t%d\t%d/%d\t%d\t%t\t%d\n", team.ID, team.LeaderID,
+				len(team.MemberList), team.TeamTypeSize, len(team.Applicants), team.Raid, team.Version)
+		}
+	case pkg.TeamSnapshot:
+		fmt.Fprintf(tw, "team %d, leader %d, %d/%d members\n\n", body.ID, body.LeaderID, len(body.MemberList), body.TeamTypeSize)
+		fmt.Fprintln(tw, "MEMBER\tJOINED\tMETHOD\tROLE\tRANK")
+		for _, member := range body.MemberList {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\n", member.Guid, member.JoinedAt.Format("2006-01-02 15:04:05"),
+				member.JoinMethod, member.Role, member.Rank)
+		}
+		fmt.Fprintf(tw, "\napplicants: %s\n", joinGuids(body.Applicants))
+	case pkg.PlayerTeam:
+		fmt.Fprintln(tw, "PLAYER\tTEAM")
+		fmt.Fprintf(tw, "%d\t%d\n", body.Guid, body.TeamID)
+	case pkg.GuidVector:
+		fmt.Fprintln(tw, "PLAYER")
+		for _, guid := range body {
+			fmt.Fprintf(tw, "%d\n", guid)
+		}
+	case pkg.AdminResult:
+		fmt.Fprintln(tw, "RESULT\tREMOVED")
+		fmt.Fprintf(tw, "%d\t%t\n", body.Result, body.Removed)
+	default:
+		return errors.New("nothing to print")
+	}
+	return tw.Flush()
+}
+
+func joinGuids(guids pkg.GuidVector) string {
+	if len(guids) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(guids))
+	for _, guid := range guids {
+		parts = append(parts, strconv.FormatUint(guid, 10))
+	}
+	return strings.Join(parts, ", ")
+}
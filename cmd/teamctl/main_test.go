@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"team/pkg"
+)
+
+func TestParseArgs(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		command string
+		ids     []uint64
+		wantErr string
+	}{
+		{args: []string{"teams"}, command: "teams", ids: []uint64{}},
+		{args: []string{"leader", "1", "101"}, command: "leader", ids: []uint64{1, 101}},
+		{args: []string{"team"}, wantErr: "team takes 1 arguments"},
+		{args: []string{"player", "x"}, wantErr: `player: invalid id "x"`},
+		{args: []string{"rename", "1"}, wantErr: `unknown command "rename"`},
+	} {
+		command, ids, err := parseArgs(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseArgs(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || command != tt.command || !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("parseArgs(%v) = %q, %v, %v, want %q, %v", tt.args, command, ids, err, tt.command, tt.ids)
+		}
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	teams := []pkg.TeamSnapshot{{ID: 1, LeaderID: 100, MemberList: pkg.MemberList{{Guid: 100}, {Guid: 101}},
+		Applicants: pkg.GuidVector{200}, TeamTypeSize: 5, Version: 3}}
+	for _, tt := range []struct {
+		body any
+		want string
+	}{
+		{teams, "ID  LEADER  MEMBERS  APPLICANTS  RAID   VERSION\n1   100     2/5      1           false  3\n"},
+		{pkg.PlayerTeam{Guid: 100, TeamID: 1}, "PLAYER  TEAM\n100     1\n"},
+		{pkg.GuidVector{7, 8}, "PLAYER\n7\n8\n"},
+		{pkg.AdminResult{Result: 0, Removed: true}, "RESULT  REMOVED\n0       true\n"},
+	} {
+		var out bytes.Buffer
+		if err := printTable(&out, tt.body); err != nil {
+			t.Errorf("printTable(%T) error = %v", tt.body, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("printTable(%T) = %q, want %q", tt.body, out.String(), tt.want)
+		}
+	}
+	if err := printTable(&bytes.Buffer{}, 42); err == nil {
+		t.Errorf("printTable(int) error = nil, want error")
+	}
+}
+
+func TestRunBackends(t *testing.T) {
+	ts := pkg.NewTeamSystem()
+	ts.CreateTeam(pkg.NewCreateTeamParam(100, []uint64{100, 101}))
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	data, _ := json.Marshal(ts.Snapshot())
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(ts.AdminHandler(nil))
+	defer server.Close()
+
+	file, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatalf("loadSnapshot() error = %v", err)
+	}
+	for name, b := range map[string]backend{"remote": newRemote(server.URL + "/"), "local": file} {
+		var out bytes.Buffer
+		if err := run(b, []string{"player", "101"}, false, &out); err != nil || out.String() != "PLAYER  TEAM\n101     1\n" {
+			t.Errorf("%s run(player) = %q, %v", name, out.String(), err)
+		}
+		out.Reset()
+		if err := run(b, []string{"leader", "1", "101"}, true, &out); err != nil || !strings.Contains(out.String(), `"result": 0`) {
+			t.Errorf("%s run(leader) = %q, %v", name, out.String(), err)
+		}
+		if err := run(b, []string{"team", "9"}, false, &out); err == nil {
+			t.Errorf("%s run(team 9) error = nil, want error", name)
+		}
+	}
+
+	if got := ts.GetLeaderIDByTeamID(1); got != 101 {
+		t.Errorf("remote leader = %v, want %v", got, 101)
+	}
+	saved, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatalf("loadSnapshot() error = %v", err)
+	}
+	if got := saved.ts.GetLeaderIDByTeamID(1); got != 101 {
+		t.Errorf("saved snapshot leader = %v, want %v", got, 101)
+	}
+}
@@ -0,0 +1,64 @@
+// Command teamreplay replays a TeamSystem journal against a fresh system and
+// reports the first call whose result differs from the recording. The system is
+// configured from the JSON encoded pkg.JournalConfig given with -config.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"team/pkg"
+)
+
+func main() {
+	configPath := flag.String("config", "", "JSON configuration of the recording system")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: teamreplay [-config FILE] journal.jsonl")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ts, err := newTeamSystem(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "teamreplay:", err)
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "teamreplay:", err)
+		os.Exit(2)
+	}
+	defer file.Close()
+
+	report, err := pkg.Replay(file, ts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "teamreplay:", err)
+		os.Exit(2)
+	}
+	if report.Divergence != nil {
+		fmt.Printf("divergence after %d entries: %s\n", report.Entries, report.Divergence)
+		os.Exit(1)
+	}
+	fmt.Printf("replayed %d entries, no divergence\n", report.Entries)
+}
+
+func newTeamSystem(configPath string) (*pkg.TeamSystem, error) {
+	config := pkg.JournalConfig{}
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("%s: %w", configPath, err)
+		}
+	}
+	return pkg.NewTeamSystemFromConfig(config)
+}
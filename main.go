@@ -1,25 +1,79 @@
+// Command team is a team rules simulator. It reads commands such as
+// "create 1 2,3 5" or "apply 1 9" from the terminal or a script file,
+// runs them against a TeamSystem and prints the state after each step.
 package main
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+
 	"team/pkg"
 )
 
 func main() {
-	// Example usage
-	ts := pkg.NewTeamSystem()
+	script := flag.String("script", "", "run the commands in this file instead of reading the terminal")
+	dump := flag.String("dump", "", "write a snapshot of the final state to this file")
+	quiet := flag.Bool("quiet", false, "do not print the state after each step")
+	flag.Parse()
+
+	sim := newSimulator(pkg.NewTeamSystem(), os.Stdout)
+	sim.showState = !*quiet
 
-	param := pkg.CreateTeamParam{
-		LeaderID:     1,
-		MemberList:   pkg.GuidVector{2, 3, 4},
-		TeamTypeSize: 5,
+	var err error
+	if *script != "" {
+		err = runScript(sim, *script)
+	} else {
+		err = runInteractive(sim, os.Stdin)
+	}
+	if err == nil && *dump != "" {
+		err = sim.dump(*dump)
 	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "team:", err)
+		os.Exit(1)
+	}
+}
 
-	result := ts.CreateTeam(param)
-	fmt.Println("Create team result:", result)
+// runScript stops at the first line that is not a valid command
+func runScript(sim *simulator, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-	fmt.Println("Team size:", ts.TeamSize())
-	fmt.Println("Last team ID:", ts.LastTeamID())
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		done, err := sim.exec(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if done {
+			break
+		}
+	}
+	return scanner.Err()
+}
 
-	// Other operations can be tested similarly
+// runInteractive reports invalid commands and keeps reading until quit or end of input
+func runInteractive(sim *simulator, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	fmt.Fprintln(sim.out, `team simulator, type "help" for commands`)
+	for {
+		fmt.Fprint(sim.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(sim.out)
+			return scanner.Err()
+		}
+		done, err := sim.exec(scanner.Text())
+		if err != nil {
+			fmt.Fprintln(sim.out, "error:", err)
+		}
+		if done {
+			return nil
+		}
+	}
 }
@@ -0,0 +1,188 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// RestoreTeamSystem creates a TeamSystem holding the teams of snapshot.
+// Raid sub-groups are rebuilt in join order and creation times are reset to the clock.
+func RestoreTeamSystem(snapshot SystemSnapshot, opts ...TeamSystemOption) *TeamSystem {
+	ts := NewTeamSystem(opts...)
+	ts.lastTeamID = snapshot.LastTeamID
+	ts.lastScheduleID = snapshot.LastScheduleID
+	for _, saved := range snapshot.Schedules {
+		schedule := saved.clone()
+		ts.schedules[schedule.ID] = &schedule
+	}
+	for _, saved := range snapshot.Teams {
+		team := &Team{
+			LeaderID:     saved.LeaderID,
+			ID:           saved.ID,
+			MemberList:   make(MemberList, 0, len(saved.MemberList)),
+			Applicants:   make(GuidVector, 0, len(saved.Applicants)),
+			TeamTypeSize: saved.TeamTypeSize,
+			CreatedAt:    ts.now(),
+			GuildID:      saved.GuildID,
+		}
+		ts.teams[team.ID] = team
+		if saved.Raid {
+			ts.raids[team.ID] = &Raid{TeamID: team.ID}
+		}
+		settings := saved.Settings.clone()
+		ts.settings[team.ID] = &settings
+		if len(saved.AutoAccept) > 0 {
+			ts.autoAccept[team.ID] = cloneAutoAcceptRules(saved.AutoAccept)
+		}
+		ts.onTeamCreated(team)
+
+		for _, member := range saved.MemberList.clone() {
+			team.MemberList = append(team.MemberList, member)
+			ts.playerLists.Store(member.Guid, team.ID)
+			ts.onMemberJoined(team, member.Guid)
+		}
+		for _, guid := range saved.Applicants {
+			team.Applicants = append(team.Applicants, guid)
+			ts.indexApplication(guid, team.ID)
+		}
+		ts.metrics.setApplicants(team.ID, len(team.Applicants))
+		team.Version = saved.Version
+	}
+	return ts
+}
+
+// StalePlayerIndex returns the players whose index entry points to a missing team or to a team they are not in
+func (ts *TeamSystem) StalePlayerIndex() GuidVector {
+	stale := make(GuidVector, 0)
+	ts.playerLists.Range(func(key, value interface{}) bool {
+		guid, teamID := key.(uint64), value.(uint64)
+		if !ts.HasMember(teamID, guid) {
+			stale = append(stale, guid)
+		}
+		return true
+	})
+	sort.Slice(stale, func(i, j int) bool { return stale[i] < stale[j] })
+	return stale
+}
+
+// RemovePlayerIndex deletes the index entry of guid if it is stale, and reports whether it was removed
+func (ts *TeamSystem) RemovePlayerIndex(guid uint64) bool {
+	teamID, ok := ts.playerLists.Load(guid)
+	if !ok || ts.HasMember(teamID.(uint64), guid) {
+		return false
+	}
+	ts.playerLists.Delete(guid)
+	return true
+}
+
+// AdminResult is the body returned by the mutating admin endpoints
+type AdminResult struct {
+	Result  uint32 `json:"result"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// PlayerTeam is the body returned by the player lookup admin endpoint
+type PlayerTeam struct {
+	Guid   uint64 `json:"guid"`
+	TeamID uint64 `json:"team_id"` // kInvalidGuid when the player is not in a team
+}
+
+// AdminHandler serves the inspection and repair endpoints used by teamctl:
+//
+//	GET    /snapshot                 SystemSnapshot
+//	GET    /teams                    []TeamSnapshot
+//	GET    /teams/{id}               TeamSnapshot
+//	GET    /players/{guid}           PlayerTeam
+//	POST   /teams/{id}/disband       AdminResult, see DisbandedTeamNoLeader
+//	POST   /teams/{id}/leader/{guid} AdminResult, see AppointLeader
+//	GET    /index/stale              GuidVector, see StalePlayerIndex
+//	DELETE /index/{guid}             AdminResult, see RemovePlayerIndex
+//
+// Every request holds mu, which must be the lock guarding ts in the running service.
+// A nil mu means the caller already serializes access to ts.
+func (ts *TeamSystem) AdminHandler(mu sync.Locker) http.Handler {
+	if mu == nil {
+		mu = noopLocker{}
+	}
+	mux := http.NewServeMux()
+	handle := func(pattern string, serve func(r *http.Request) (any, int)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			body, status := serve(r)
+			mu.Unlock()
+			writeAdminJSON(w, status, body)
+		})
+	}
+
+	handle("GET /snapshot", func(*http.Request) (any, int) {
+		return ts.Snapshot(), http.StatusOK
+	})
+	handle("GET /teams", func(*http.Request) (any, int) {
+		return ts.Snapshot().Teams, http.StatusOK
+	})
+	handle("GET /teams/{id}", func(r *http.Request) (any, int) {
+		teamID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		team, ok := ts.TeamSnapshot(teamID)
+		if !ok {
+			return adminError("team not found"), http.StatusNotFound
+		}
+		return team, http.StatusOK
+	})
+	handle("GET /players/{guid}", func(r *http.Request) (any, int) {
+		guid, err := strconv.ParseUint(r.PathValue("guid"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		return PlayerTeam{Guid: guid, TeamID: ts.GetTeamID(guid)}, http.StatusOK
+	})
+	handle("POST /teams/{id}/disband", func(r *http.Request) (any, int) {
+		teamID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		return AdminResult{Result: ts.DisbandedTeamNoLeader(teamID)}, http.StatusOK
+	})
+	handle("POST /teams/{id}/leader/{guid}", func(r *http.Request) (any, int) {
+		teamID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		guid, err := strconv.ParseUint(r.PathValue("guid"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		return AdminResult{Result: ts.AppointLeader(teamID, ts.GetLeaderIDByTeamID(teamID), guid)}, http.StatusOK
+	})
+	handle("GET /index/stale", func(*http.Request) (any, int) {
+		return ts.StalePlayerIndex(), http.StatusOK
+	})
+	handle("DELETE /index/{guid}", func(r *http.Request) (any, int) {
+		guid, err := strconv.ParseUint(r.PathValue("guid"), 10, 64)
+		if err != nil {
+			return adminError(err.Error()), http.StatusBadRequest
+		}
+		return AdminResult{Removed: ts.RemovePlayerIndex(guid)}, http.StatusOK
+	})
+	return mux
+}
+
+type noopLocker struct{}
+
+func (noopLocker) Lock()   {}
+func (noopLocker) Unlock() {}
+
+func adminError(message string) map[string]string {
+	return map[string]string{"error": message}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
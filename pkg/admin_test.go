@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRestoreTeamSystem(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	teamID := ts.LastTeamID()
+	ts.ApplyToTeam(teamID, 200)
+	ts.SetMemberRole(teamID, 101, 101, RoleHealer)
+	ts.CreateRaid(NewCreateTeamParam(300, []uint64{300, 301}))
+	raidID := ts.LastTeamID()
+
+	snapshot := ts.Snapshot()
+	restored := RestoreTeamSystem(snapshot)
+	if got := restored.Snapshot(); !reflect.DeepEqual(got, snapshot) {
+		t.Errorf("RestoreTeamSystem().Snapshot() = %+v, want %+v", got, snapshot)
+	}
+	if got := restored.GetTeamID(101); got != teamID {
+		t.Errorf("GetTeamID(101) = %v, want %v", got, teamID)
+	}
+	if !restored.IsRaid(raidID) || restored.RaidSubGroupOf(raidID, 301) != 0 {
+		t.Errorf("restored raid layout = %v, want raid with 301 in group 0", restored.RaidLayout(raidID))
+	}
+
+	restored.CreateTeam(NewCreateTeamParam(400, []uint64{400}))
+	if got := restored.LastTeamID(); got != raidID+1 {
+		t.Errorf("LastTeamID() after restore = %v, want %v", got, raidID+1)
+	}
+}
+
+func TestRemovePlayerIndex(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	ts.playerLists.Store(uint64(500), uint64(42))
+	ts.playerLists.Store(uint64(501), ts.LastTeamID())
+
+	if got, want := ts.StalePlayerIndex(), (GuidVector{500, 501}); !reflect.DeepEqual(got, want) {
+		t.Errorf("StalePlayerIndex() = %v, want %v", got, want)
+	}
+	if ts.RemovePlayerIndex(100) {
+		t.Errorf("RemovePlayerIndex(100) = true, want false for a member")
+	}
+	if !ts.RemovePlayerIndex(500) || ts.HasTeam(500) {
+		t.Errorf("RemovePlayerIndex(500) did not remove the stale entry")
+	}
+	if got, want := ts.StalePlayerIndex(), (GuidVector{501}); !reflect.DeepEqual(got, want) {
+		t.Errorf("StalePlayerIndex() = %v, want %v", got, want)
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	teamID := ts.LastTeamID()
+	handler := ts.AdminHandler(&sync.Mutex{})
+
+	serve := func(method, path string, out any) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		if out != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatalf("%s %s body %q: %v", method, path, rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	var player PlayerTeam
+	serve("GET", "/players/101", &player)
+	if player.TeamID != teamID {
+		t.Errorf("GET /players/101 = %+v, want team %v", player, teamID)
+	}
+
+	var result AdminResult
+	serve("POST", "/teams/1/leader/101", &result)
+	if result.Result != kOK || ts.GetLeaderIDByTeamID(teamID) != 101 {
+		t.Errorf("POST leader = %+v, leader %v, want 101", result, ts.GetLeaderIDByTeamID(teamID))
+	}
+
+	if code := serve("GET", "/teams/9", nil); code != 404 {
+		t.Errorf("GET /teams/9 status = %v, want %v", code, 404)
+	}
+	if code := serve("GET", "/teams/x", nil); code != 400 {
+		t.Errorf("GET /teams/x status = %v, want %v", code, 400)
+	}
+
+	serve("POST", "/teams/1/disband", &result)
+	var teams []TeamSnapshot
+	serve("GET", "/teams", &teams)
+	if result.Result != kOK || len(teams) != 0 {
+		t.Errorf("after disband result = %v and teams = %v, want none", result.Result, teams)
+	}
+}
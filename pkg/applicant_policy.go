@@ -0,0 +1,120 @@
+package pkg
+
+// ApplicantPolicy decides the order of the applicant list and what happens once it is full
+type ApplicantPolicy uint8
+
+const (
+	ApplicantFIFO           ApplicantPolicy = iota // Apply order, the oldest applicant is evicted when full
+	ApplicantRejectWhenFull                        // Apply order, new applicants get kTeamApplyListFull when full
+	ApplicantByScore                               // Highest score first, the lowest is evicted for a higher one
+)
+
+// ApplicantScorer rates an applicant of a team, higher is better
+type ApplicantScorer func(teamID uint64, applicant PlayerInfo) int64
+
+// Weights of DefaultApplicantScore
+const (
+	kScoreFriendInTeam = 100 // Applicant is a friend of a member
+	kScoreRoleNeeded   = 50  // No member plays the role of the applicant yet
+)
+
+// WithApplicantPolicy sets the applicant policy, scorer is used by ApplicantByScore and
+// defaults to DefaultApplicantScore when nil. Evicted applicants are reported as
+// TeamEventApplicantEvicted events.
+func WithApplicantPolicy(policy ApplicantPolicy, scorer ApplicantScorer) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.applicantPolicy = policy
+		ts.applicantScorer = scorer
+	}
+}
+
+// DefaultApplicantScore is the level of the applicant plus bonuses for having a friend
+// in the team and for playing a role no member plays
+func (ts *TeamSystem) DefaultApplicantScore(teamID uint64, applicant PlayerInfo) int64 {
+	score := int64(applicant.Level)
+	if ts.HasFriendInTeam(teamID, applicant.Guid) {
+		score += kScoreFriendInTeam
+	}
+	if team, ok := ts.teams[teamID]; ok && applicant.Role != RoleNone {
+		needed := true
+		for _, member := range team.MemberList {
+			if member.Role == applicant.Role {
+				needed = false
+				break
+			}
+		}
+		if needed {
+			score += kScoreRoleNeeded
+		}
+	}
+	return score
+}
+
+func (ts *TeamSystem) applicantScore(teamID uint64, applicant PlayerInfo) int64 {
+	scorer := ts.applicantScorer
+	if scorer == nil {
+		scorer = ts.DefaultApplicantScore
+	}
+	return scorer(teamID, applicant)
+}
+
+// addApplicant places guid in the applicant list of the team according to the applicant policy
+func (ts *TeamSystem) addApplicant(team *Team, guid uint64) uint32 {
+	if ts.applicantPolicy == ApplicantByScore {
+		return ts.addApplicantByScore(team, guid)
+	}
+	if len(team.Applicants) >= kMaxApplicantSize {
+		if ts.applicantPolicy == ApplicantRejectWhenFull {
+			return kTeamApplyListFull
+		}
+		ts.evictApplicant(team, 0)
+	}
+	team.Applicants = append(team.Applicants, guid)
+	ts.indexApplication(guid, team.ID)
+	return kOK
+}
+
+// addApplicantByScore keeps the list ordered by score, ties in apply order. Scores are
+// computed again on every application since player attributes change over time.
+func (ts *TeamSystem) addApplicantByScore(team *Team, guid uint64) uint32 {
+	infos := ts.PlayerInfosOf(append(GuidVector{guid}, team.Applicants...))
+	score := ts.applicantScore(team.ID, infos[guid])
+	scores := make([]int64, len(team.Applicants))
+	for idx, applicant := range team.Applicants {
+		scores[idx] = ts.applicantScore(team.ID, infos[applicant])
+	}
+
+	if len(team.Applicants) >= kMaxApplicantSize {
+		// The lowest score is evicted, the newest of equal scores first
+		lowest := 0
+		for idx := range scores {
+			if scores[idx] <= scores[lowest] {
+				lowest = idx
+			}
+		}
+		if score <= scores[lowest] {
+			return kTeamApplyListFull
+		}
+		ts.evictApplicant(team, lowest)
+		scores = append(scores[:lowest], scores[lowest+1:]...)
+	}
+
+	pos := len(scores)
+	for idx, other := range scores {
+		if score > other {
+			pos = idx
+			break
+		}
+	}
+	team.Applicants = append(team.Applicants, kInvalidGuid)
+	copy(team.Applicants[pos+1:], team.Applicants[pos:])
+	team.Applicants[pos] = guid
+	ts.indexApplication(guid, team.ID)
+	return kOK
+}
+
+func (ts *TeamSystem) evictApplicant(team *Team, idx int) {
+	guid := team.Applicants[idx]
+	ts.removeApplicantAt(team, idx)
+	ts.emit(TeamEvent{Type: TeamEventApplicantEvicted, TeamID: team.ID, Guid: guid})
+}
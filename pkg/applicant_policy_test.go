@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplicantRejectWhenFull(t *testing.T) {
+	ts := NewTeamSystem(WithApplicantPolicy(ApplicantRejectWhenFull, nil))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+
+	for guid := uint64(200); guid < 200+kMaxApplicantSize; guid++ {
+		if got := ts.ApplyToTeam(teamID, guid); got != kOK {
+			t.Fatalf("ApplyToTeam(%v) = %v, want %v", guid, got, kOK)
+		}
+	}
+	if got := ts.ApplyToTeam(teamID, 999); got != kTeamApplyListFull {
+		t.Errorf("ApplyToTeam() on a full list = %v, want %v", got, kTeamApplyListFull)
+	}
+	if got := ts.FirstApplicant(teamID); got != 200 {
+		t.Errorf("FirstApplicant() = %v, want %v", got, 200)
+	}
+	if got := ts.ListMyApplications(999); len(got) != 0 {
+		t.Errorf("ListMyApplications() of a rejected applicant = %v, want none", got)
+	}
+}
+
+func TestApplicantFIFOEvictionEvent(t *testing.T) {
+	ts := NewTeamSystem()
+	evicted := make(GuidVector, 0)
+	ts.SubscribeEvents(func(event TeamEvent) {
+		if event.Type == TeamEventApplicantEvicted {
+			evicted = append(evicted, event.Guid)
+		}
+	})
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	for guid := uint64(200); guid < 202+kMaxApplicantSize; guid++ {
+		ts.ApplyToTeam(ts.LastTeamID(), guid)
+	}
+	if want := (GuidVector{200, 201}); !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted applicants = %v, want %v", evicted, want)
+	}
+}
+
+func TestApplicantByScore(t *testing.T) {
+	levels := map[uint64]uint32{300: 60, 301: 10, 302: 30, 303: 30}
+	ts := NewTeamSystem(WithApplicantPolicy(ApplicantByScore, nil))
+	ts.SetPlayerInfoProvider(PlayerInfoFunc(func(guid uint64) (PlayerInfo, bool) {
+		if guid == 304 {
+			return PlayerInfo{Level: 5, Role: RoleHealer}, true
+		}
+		level, ok := levels[guid]
+		return PlayerInfo{Level: level, Role: RoleDamage}, ok
+	}), 0)
+	relations := NewLocalRelationProvider()
+	relations.AddFriend(100, 305)
+	ts.SetRelationProvider(relations)
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+	ts.SetMemberRole(teamID, 100, 100, RoleDamage)
+
+	for guid := uint64(300); guid <= 305; guid++ {
+		ts.ApplyToTeam(teamID, guid)
+	}
+	// Friend 305 scores 100, healer 304 scores 55, ties keep apply order
+	want := GuidVector{305, 300, 304, 302, 303, 301}
+	if got := ts.teams[teamID].Applicants; !reflect.DeepEqual(got, want) {
+		t.Errorf("applicants = %v, want %v", got, want)
+	}
+}
+
+func TestApplicantByScoreEviction(t *testing.T) {
+	ts := NewTeamSystem(WithApplicantPolicy(ApplicantByScore, func(_ uint64, applicant PlayerInfo) int64 {
+		return int64(applicant.Guid % 1000)
+	}))
+	evicted := make(GuidVector, 0)
+	ts.SubscribeEvents(func(event TeamEvent) {
+		if event.Type == TeamEventApplicantEvicted {
+			evicted = append(evicted, event.Guid)
+		}
+	})
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+
+	for guid := uint64(1100); guid < 1100+kMaxApplicantSize; guid++ {
+		ts.ApplyToTeam(teamID, guid)
+	}
+	if got := ts.ApplyToTeam(teamID, 2050); got != kTeamApplyListFull {
+		t.Errorf("ApplyToTeam() with a low score = %v, want %v", got, kTeamApplyListFull)
+	}
+	if got := ts.ApplyToTeam(teamID, 2500); got != kOK {
+		t.Errorf("ApplyToTeam() with a high score = %v, want %v", got, kOK)
+	}
+	if want := (GuidVector{1100}); !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted applicants = %v, want %v", evicted, want)
+	}
+	if got := ts.FirstApplicant(teamID); got != 2500 {
+		t.Errorf("FirstApplicant() = %v, want %v", got, 2500)
+	}
+	if ts.IsApplicant(teamID, 1100) || len(ts.ListMyApplications(1100)) != 0 {
+		t.Errorf("evicted applicant 1100 is still listed")
+	}
+}
@@ -0,0 +1,80 @@
+package pkg
+
+// WithMaxApplications caps the number of teams a player may apply to at once, 0 means no cap
+func WithMaxApplications(n int) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.maxApplications = n
+	}
+}
+
+// ListMyApplications returns the teams guid has applied to, oldest application first
+func (ts *TeamSystem) ListMyApplications(guid uint64) GuidVector {
+	return append(GuidVector{}, ts.applications[guid]...)
+}
+
+// WithdrawApplication removes the application of guid to the team
+func (ts *TeamSystem) WithdrawApplication(teamID, guid uint64) uint32 {
+	call := ts.beginOp(OpWithdrawApplication, teamID, guid, guid)
+	return ts.endOp(call, ts.withdrawApplication(teamID, guid))
+}
+
+func (ts *TeamSystem) withdrawApplication(teamID, guid uint64) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	idx := ts.FindApplicantIndex(team, guid)
+	if idx == -1 {
+		return kTeamNotInApplicantList
+	}
+	ts.removeApplicantAt(team, idx)
+	team.Version++
+	return kOK
+}
+
+// removeApplicantAt removes one applicant of the team together with its application index entry
+func (ts *TeamSystem) removeApplicantAt(team *Team, idx int) {
+	guid := team.Applicants[idx]
+	team.Applicants = append(team.Applicants[:idx], team.Applicants[idx+1:]...)
+	ts.unindexApplication(guid, team.ID)
+}
+
+func (ts *TeamSystem) indexApplication(guid, teamID uint64) {
+	ts.applications[guid] = append(ts.applications[guid], teamID)
+}
+
+func (ts *TeamSystem) unindexApplication(guid, teamID uint64) {
+	teamIDs := ts.applications[guid]
+	for idx, other := range teamIDs {
+		if other == teamID {
+			teamIDs = append(teamIDs[:idx], teamIDs[idx+1:]...)
+			break
+		}
+	}
+	if len(teamIDs) == 0 {
+		delete(ts.applications, guid)
+	} else {
+		ts.applications[guid] = teamIDs
+	}
+}
+
+// applicationsMemberJoined withdraws the remaining applications of guid once it is in a team
+func (ts *TeamSystem) applicationsMemberJoined(guid uint64) {
+	for _, teamID := range ts.ListMyApplications(guid) {
+		team, ok := ts.teams[teamID]
+		if !ok {
+			continue
+		}
+		if idx := ts.FindApplicantIndex(team, guid); idx != -1 {
+			ts.removeApplicantAt(team, idx)
+			team.Version++
+			ts.metrics.setApplicants(teamID, len(team.Applicants))
+		}
+	}
+}
+
+func (ts *TeamSystem) applicationsTeamErased(team *Team) {
+	for _, guid := range team.Applicants {
+		ts.unindexApplication(guid, team.ID)
+	}
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplicationIndex(t *testing.T) {
+	ts := NewTeamSystem(WithMaxApplications(2))
+	teamIDs := make(GuidVector, 0, 3)
+	for leader := uint64(100); leader < 103; leader++ {
+		ts.CreateTeam(NewCreateTeamParam(leader, []uint64{leader}))
+		teamIDs = append(teamIDs, ts.LastTeamID())
+	}
+
+	ts.ApplyToTeam(teamIDs[0], 500)
+	ts.ApplyToTeam(teamIDs[1], 500)
+	if got := ts.ApplyToTeam(teamIDs[2], 500); got != kTeamTooManyApplications {
+		t.Errorf("ApplyToTeam() over the cap = %v, want %v", got, kTeamTooManyApplications)
+	}
+	if got, want := ts.ListMyApplications(500), teamIDs[:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMyApplications() = %v, want %v", got, want)
+	}
+
+	if got := ts.WithdrawApplication(teamIDs[0], 500); got != kOK {
+		t.Errorf("WithdrawApplication() = %v, want %v", got, kOK)
+	}
+	if got := ts.WithdrawApplication(teamIDs[0], 500); got != kTeamNotInApplicantList {
+		t.Errorf("WithdrawApplication() again = %v, want %v", got, kTeamNotInApplicantList)
+	}
+	if got := ts.ApplyToTeam(teamIDs[2], 500); got != kOK {
+		t.Errorf("ApplyToTeam() after withdrawing = %v, want %v", got, kOK)
+	}
+
+	// Joining one team withdraws every other application
+	if got := ts.JoinTeam(teamIDs[1], 500); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.ListMyApplications(500); len(got) != 0 {
+		t.Errorf("ListMyApplications() after joining = %v, want none", got)
+	}
+	if ts.IsApplicant(teamIDs[2], 500) {
+		t.Errorf("IsApplicant() on another team after joining = true, want false")
+	}
+}
+
+func TestApplicationIndexCleanup(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	first := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}))
+	second := ts.LastTeamID()
+
+	ts.ApplyToTeam(first, 500)
+	ts.ApplyToTeam(second, 500)
+	ts.ApplyToTeam(first, 501)
+	ts.Disbanded(first, 100)
+	if got, want := ts.ListMyApplications(500), (GuidVector{second}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMyApplications() after disband = %v, want %v", got, want)
+	}
+	if got := ts.ListMyApplications(501); len(got) != 0 {
+		t.Errorf("ListMyApplications() after disband = %v, want none", got)
+	}
+
+	ts.ClearApplyList(second)
+	if got := ts.ListMyApplications(500); len(got) != 0 {
+		t.Errorf("ListMyApplications() after ClearApplyList = %v, want none", got)
+	}
+
+	// Evicting the oldest applicant of a full list also drops its index entry
+	for guid := uint64(600); guid <= 600+kMaxApplicantSize; guid++ {
+		ts.ApplyToTeam(second, guid)
+	}
+	if got := ts.ListMyApplications(600); len(got) != 0 {
+		t.Errorf("ListMyApplications() of an evicted applicant = %v, want none", got)
+	}
+}
@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// kAuditWindowSize is the number of audit entries kept in memory
+const kAuditWindowSize = 1024
+
+// AuditEntry records one administrative operation, see Op.audited, successful or not.
+// ActorID is kInvalidGuid when the operation was issued by the system.
+type AuditEntry struct {
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	Action   Op        `json:"action"`
+	TeamID   uint64    `json:"team_id"`
+	ActorID  uint64    `json:"actor_id"`
+	TargetID uint64    `json:"target_id"`
+	Result   uint32    `json:"result"`
+}
+
+// auditLog keeps the latest audit entries and appends every entry to an optional sink
+type auditLog struct {
+	window  *ring[AuditEntry]
+	lastSeq uint64
+	sink    io.Writer
+	sinkErr error // First error returned by the sink
+}
+
+func newAuditLog(windowSize int) *auditLog {
+	return &auditLog{window: newRing[AuditEntry](windowSize)}
+}
+
+// SetAuditSink sets the writer receiving every audit entry as a JSON line, nil disables it
+func (ts *TeamSystem) SetAuditSink(sink io.Writer) {
+	ts.audit.sink = sink
+	ts.audit.sinkErr = nil
+}
+
+// AuditSinkErr returns the first error the audit sink failed with
+func (ts *TeamSystem) AuditSinkErr() error {
+	return ts.audit.sinkErr
+}
+
+// AuditByTeam returns the audit entries in the in-memory window concerning the team, oldest first
+func (ts *TeamSystem) AuditByTeam(teamID uint64) []AuditEntry {
+	return ts.filterAudit(func(entry AuditEntry) bool {
+		return entry.TeamID == teamID
+	})
+}
+
+// AuditByPlayer returns the audit entries in the in-memory window where guid is the actor or the target
+func (ts *TeamSystem) AuditByPlayer(guid uint64) []AuditEntry {
+	return ts.filterAudit(func(entry AuditEntry) bool {
+		return entry.ActorID == guid || entry.TargetID == guid
+	})
+}
+
+func (ts *TeamSystem) filterAudit(match func(AuditEntry) bool) []AuditEntry {
+	entries := make([]AuditEntry, 0)
+	for _, entry := range ts.audit.window.all() {
+		if match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (ts *TeamSystem) recordAudit(call opCall, result uint32) {
+	log := ts.audit
+	log.lastSeq++
+	entry := AuditEntry{
+		Seq:      log.lastSeq,
+		Time:     ts.now(),
+		Action:   call.op,
+		TeamID:   call.teamID,
+		ActorID:  call.actorID,
+		TargetID: call.targetID,
+		Result:   result,
+	}
+	log.window.push(entry)
+
+	if log.sink == nil || log.sinkErr != nil {
+		return
+	}
+	if err := json.NewEncoder(log.sink).Encode(entry); err != nil {
+		log.sinkErr = err
+	}
+}
+
+// AuditFileWriter appends audit lines to a file and rotates it once it would exceed maxBytes,
+// keeping up to maxBackups older files named path.1 (newest) to path.N
+type AuditFileWriter struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func NewAuditFileWriter(path string, maxBytes int64, maxBackups int) (*AuditFileWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &AuditFileWriter{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+		file:       file,
+		size:       info.Size(),
+	}, nil
+}
+
+func (w *AuditFileWriter) Write(p []byte) (int, error) {
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *AuditFileWriter) Close() error {
+	return w.file.Close()
+}
+
+func (w *AuditFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if w.maxBackups > 0 {
+		for i := w.maxBackups - 1; i > 0; i-- {
+			from := fmt.Sprintf("%s.%d", w.path, i)
+			if _, err := os.Stat(from); err == nil {
+				if err := os.Rename(from, fmt.Sprintf("%s.%d", w.path, i+1)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Rename(w.path, w.path+".1"); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.size = 0
+	return nil
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditAdministrativeActions(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(100)
+
+	ts.CreateTeam(NewCreateTeamParam(leaderID, []uint64{100, 101, 102}))
+	teamID := ts.LastTeamID()
+	ts.ApplyToTeam(teamID, 200)
+	ts.ApplyToTeam(teamID, 201)
+
+	ts.KickMember(teamID, 101, 102)
+	ts.KickMember(teamID, leaderID, 102)
+	ts.AppointLeader(teamID, leaderID, 101)
+	ts.DelApplicant(teamID, 200)
+	ts.ClearApplyList(teamID)
+	ts.DisbandedTeamNoLeader(teamID)
+	ts.Disbanded(teamID, 101)
+
+	want := []AuditEntry{
+		{Action: OpKickMember, TeamID: teamID, ActorID: 101, TargetID: 102, Result: kTeamKickNotLeader},
+		{Action: OpKickMember, TeamID: teamID, ActorID: leaderID, TargetID: 102, Result: kOK},
+		{Action: OpAppointLeader, TeamID: teamID, ActorID: leaderID, TargetID: 101, Result: kOK},
+		{Action: OpDelApplicant, TeamID: teamID, ActorID: kInvalidGuid, TargetID: 200, Result: kOK},
+		{Action: OpClearApplyList, TeamID: teamID, ActorID: kInvalidGuid, TargetID: kInvalidGuid, Result: kOK},
+		{Action: OpDisbandedTeamNoLeader, TeamID: teamID, ActorID: kInvalidGuid, TargetID: 101, Result: kOK},
+		{Action: OpDisbanded, TeamID: teamID, ActorID: 101, TargetID: kInvalidGuid, Result: kTeamHasNotTeamId},
+	}
+	entries := ts.AuditByTeam(teamID)
+	if len(entries) != len(want) {
+		t.Fatalf("AuditByTeam() = %v entries, want %v", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Seq != uint64(i+1) {
+			t.Errorf("entry %v Seq = %v, want %v", i, entry.Seq, i+1)
+		}
+		entry.Seq, entry.Time = 0, want[i].Time
+		if entry != want[i] {
+			t.Errorf("entry %v = %v, want %v", i, entry, want[i])
+		}
+	}
+
+	if got := len(ts.AuditByPlayer(102)); got != 2 {
+		t.Errorf("AuditByPlayer(102) = %v entries, want %v", got, 2)
+	}
+	if got := len(ts.AuditByPlayer(101)); got != 4 {
+		t.Errorf("AuditByPlayer(101) = %v entries, want %v", got, 4)
+	}
+}
+
+func TestAuditWindowAndFileRotation(t *testing.T) {
+	ts := NewTeamSystem()
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	writer, err := NewAuditFileWriter(path, 1024, 2)
+	if err != nil {
+		t.Fatalf("NewAuditFileWriter() error = %v", err)
+	}
+	ts.SetAuditSink(writer)
+
+	total := kAuditWindowSize + 10
+	for i := 0; i < total; i++ {
+		ts.ClearApplyList(1)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := ts.AuditSinkErr(); err != nil {
+		t.Errorf("AuditSinkErr() = %v", err)
+	}
+
+	entries := ts.AuditByTeam(1)
+	if got := len(entries); got != kAuditWindowSize {
+		t.Errorf("AuditByTeam() = %v entries, want %v", got, kAuditWindowSize)
+	}
+	if got := entries[0].Seq; got != uint64(total-kAuditWindowSize+1) {
+		t.Errorf("oldest Seq = %v, want %v", got, total-kAuditWindowSize+1)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Stat(%v) error = %v", name, err)
+		}
+		if info.Size() > 1024 {
+			t.Errorf("%v size = %v, want at most %v", name, info.Size(), 1024)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no more than 2 backups, Stat() error = %v", err)
+	}
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	var last AuditEntry
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+	}
+	if last.Seq != uint64(total) || last.Action != OpClearApplyList || last.Result != kTeamHasNotTeamId {
+		t.Errorf("last entry on disk = %v, want seq %v", last, total)
+	}
+}
@@ -0,0 +1,97 @@
+package pkg
+
+// AutoAcceptRule describes applicants a team takes in without approval.
+// Every condition of the rule must hold, zero values leave a condition out.
+type AutoAcceptRule struct {
+	MinLevel       uint32       `json:"min_level,omitempty"`
+	MaxLevel       uint32       `json:"max_level,omitempty"`
+	Roles          []MemberRole `json:"roles,omitempty"`    // Any of the roles
+	GuildID        uint64       `json:"guild_id,omitempty"` // Applicant must be in this guild
+	FriendOfMember bool         `json:"friend_of_member,omitempty"`
+}
+
+func (r AutoAcceptRule) clone() AutoAcceptRule {
+	r.Roles = append([]MemberRole(nil), r.Roles...)
+	return r
+}
+
+func cloneAutoAcceptRules(rules []AutoAcceptRule) []AutoAcceptRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	cloned := make([]AutoAcceptRule, len(rules))
+	for idx, rule := range rules {
+		cloned[idx] = rule.clone()
+	}
+	return cloned
+}
+
+// SetAutoAcceptRules replaces the auto-accept rules of the team, an applicant matching any rule
+// joins directly from ApplyToTeam. Only the leader and raid assistants may set them.
+func (ts *TeamSystem) SetAutoAcceptRules(teamID, editorID uint64, rules []AutoAcceptRule) uint32 {
+	call := ts.beginOp(OpSetAutoAcceptRules, teamID, editorID, kInvalidGuid)
+	call.args = &OpArgs{Rules: cloneAutoAcceptRules(rules)}
+	return ts.endOp(call, ts.setAutoAcceptRules(teamID, editorID, rules))
+}
+
+func (ts *TeamSystem) setAutoAcceptRules(teamID, editorID uint64, rules []AutoAcceptRule) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if team.LeaderID != editorID && !ts.IsRaidAssistant(teamID, editorID) {
+		return kTeamSettingsNotAuthorized
+	}
+	if len(rules) == 0 {
+		delete(ts.autoAccept, teamID)
+	} else {
+		ts.autoAccept[teamID] = cloneAutoAcceptRules(rules)
+	}
+	team.Version++
+	return kOK
+}
+
+// AutoAcceptRulesOf returns a copy of the auto-accept rules of the team
+func (ts *TeamSystem) AutoAcceptRulesOf(teamID uint64) []AutoAcceptRule {
+	return cloneAutoAcceptRules(ts.autoAccept[teamID])
+}
+
+// autoAccepts reports whether guid matches any auto-accept rule of the team
+func (ts *TeamSystem) autoAccepts(teamID, guid uint64) bool {
+	rules := ts.autoAccept[teamID]
+	if len(rules) == 0 {
+		return false
+	}
+	info := ts.PlayerInfoOf(guid)
+	for _, rule := range rules {
+		if ts.matchesAutoAccept(teamID, rule, info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts *TeamSystem) matchesAutoAccept(teamID uint64, rule AutoAcceptRule, info PlayerInfo) bool {
+	if info.Level < rule.MinLevel || (rule.MaxLevel != 0 && info.Level > rule.MaxLevel) {
+		return false
+	}
+	if len(rule.Roles) > 0 {
+		matched := false
+		for _, role := range rule.Roles {
+			if role == info.Role {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if rule.GuildID != kInvalidGuid && !ts.IsGuildMember(rule.GuildID, info.Guid) {
+		return false
+	}
+	if rule.FriendOfMember && !ts.HasFriendInTeam(teamID, info.Guid) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package pkg
+
+import "testing"
+
+func TestAutoAcceptRules(t *testing.T) {
+	players := NewLocalPlayerInfoProvider()
+	for _, info := range []PlayerInfo{
+		{Guid: 200, Level: 70, Role: RoleHealer},
+		{Guid: 201, Level: 40, Role: RoleHealer},
+		{Guid: 202, Level: 70, Role: RoleDamage, GuildID: 9},
+		{Guid: 203, Level: 20, Role: RoleTank},
+	} {
+		players.Set(info)
+	}
+	ts := NewTeamSystem(WithPlayerInfoProvider(players, 0))
+	relations := NewLocalRelationProvider()
+	relations.AddFriend(101, 203)
+	ts.SetRelationProvider(relations)
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}, 6))
+	teamID := ts.LastTeamID()
+	rules := []AutoAcceptRule{
+		{MinLevel: 60, MaxLevel: 80, Roles: []MemberRole{RoleHealer, RoleTank}},
+		{GuildID: 9},
+		{FriendOfMember: true},
+	}
+	if got := ts.SetAutoAcceptRules(teamID, 101, rules); got != kTeamSettingsNotAuthorized {
+		t.Errorf("SetAutoAcceptRules() by a member = %v, want %v", got, kTeamSettingsNotAuthorized)
+	}
+	if got := ts.SetAutoAcceptRules(teamID, 100, rules); got != kOK {
+		t.Errorf("SetAutoAcceptRules() = %v, want %v", got, kOK)
+	}
+
+	for _, tt := range []struct {
+		guid   uint64
+		joined bool
+	}{
+		{200, true},  // Level and role
+		{201, false}, // Level too low
+		{202, true},  // Guild
+		{203, true},  // Friend of a member
+		{204, false}, // Unknown player
+	} {
+		if got := ts.ApplyToTeam(teamID, tt.guid); got != kOK {
+			t.Errorf("ApplyToTeam(%v) = %v, want %v", tt.guid, got, kOK)
+		}
+		if got := ts.HasMember(teamID, tt.guid); got != tt.joined {
+			t.Errorf("HasMember(%v) = %v, want %v", tt.guid, got, tt.joined)
+		}
+		if got := ts.IsApplicant(teamID, tt.guid); got == tt.joined {
+			t.Errorf("IsApplicant(%v) = %v, want %v", tt.guid, got, !tt.joined)
+		}
+	}
+	if member, _ := ts.TeamMemberOf(teamID, 200); member.JoinMethod != JoinAutoAccepted {
+		t.Errorf("JoinMethod = %v, want %v", member.JoinMethod, JoinAutoAccepted)
+	}
+
+	// A full team rejects applicants before the rules are checked
+	ts.JoinTeam(teamID, 206)
+	if got := ts.ApplyToTeam(teamID, 205); got != kTeamMembersFull {
+		t.Errorf("ApplyToTeam() on a full team = %v, want %v", got, kTeamMembersFull)
+	}
+}
+
+func TestAutoAcceptWithdrawsOtherApplications(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	first := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}))
+	second := ts.LastTeamID()
+	ts.SetAutoAcceptRules(second, 200, []AutoAcceptRule{{}})
+
+	ts.ApplyToTeam(first, 300)
+	ts.ApplyToTeam(second, 300)
+	if !ts.HasMember(second, 300) || ts.IsApplicant(first, 300) {
+		t.Errorf("auto-accepted player 300 should be in team %v with no application to team %v", second, first)
+	}
+
+	snapshot, _ := ts.TeamSnapshot(second)
+	if len(snapshot.AutoAccept) != 1 {
+		t.Errorf("TeamSnapshot().AutoAccept = %v, want one rule", snapshot.AutoAccept)
+	}
+	ts.Disbanded(second, 200)
+	if got := ts.AutoAcceptRulesOf(second); got != nil {
+		t.Errorf("AutoAcceptRulesOf() after disband = %v, want nil", got)
+	}
+}
+
+func TestAutoAcceptIgnoresApplicationCap(t *testing.T) {
+	players := NewLocalPlayerInfoProvider()
+	players.Set(PlayerInfo{Guid: 200, Level: 70})
+	ts := NewTeamSystem(WithMaxApplications(1), WithPlayerInfoProvider(players, 0))
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	ts.ApplyToTeam(ts.LastTeamID(), 200)
+	ts.CreateTeam(NewCreateTeamParam(101, []uint64{101}))
+	if got := ts.ApplyToTeam(ts.LastTeamID(), 200); got != kTeamTooManyApplications {
+		t.Errorf("ApplyToTeam() over the cap = %v, want %v", got, kTeamTooManyApplications)
+	}
+	ts.SetAutoAcceptRules(ts.LastTeamID(), 101, []AutoAcceptRule{{MinLevel: 60}})
+	if got := ts.ApplyToTeam(ts.LastTeamID(), 200); got != kOK {
+		t.Errorf("ApplyToTeam() auto-accepted over the cap = %v, want %v", got, kOK)
+	}
+	if got := ts.GetTeamID(200); got != ts.LastTeamID() {
+		t.Errorf("GetTeamID() = %v, want %v", got, ts.LastTeamID())
+	}
+}
@@ -0,0 +1,28 @@
+package pkg
+
+// TeamEventType identifies what changed in a TeamEvent
+type TeamEventType uint8
+
+const (
+	TeamEventSettingsChanged  TeamEventType = iota + 1
+	TeamEventApplicantEvicted               // Guid was dropped from a full applicant list
+)
+
+// TeamEvent is emitted to the event handlers after a team changes
+type TeamEvent struct {
+	Type    TeamEventType
+	TeamID  uint64
+	Guid    uint64 // Player who caused or is affected by the change
+	Version uint64 // Version of the changed data, when it has one
+}
+
+// SubscribeEvents adds a handler called synchronously for every emitted event
+func (ts *TeamSystem) SubscribeEvents(handler func(TeamEvent)) {
+	ts.eventHandlers = append(ts.eventHandlers, handler)
+}
+
+func (ts *TeamSystem) emit(event TeamEvent) {
+	for _, handler := range ts.eventHandlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import "sort"
+
+// GuildMembership answers whether a player belongs to a guild
+type GuildMembership interface {
+	IsGuildMember(guildID, guid uint64) bool
+}
+
+// GuildMembershipFunc adapts a function to GuildMembership
+type GuildMembershipFunc func(guildID, guid uint64) bool
+
+func (f GuildMembershipFunc) IsGuildMember(guildID, guid uint64) bool {
+	return f(guildID, guid)
+}
+
+// WithGuildMembership answers the guild checks of guild-only teams with guilds,
+// without it membership is read from PlayerInfo.GuildID
+func WithGuildMembership(guilds GuildMembership) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.SetGuildMembership(guilds)
+	}
+}
+
+// SetGuildMembership replaces the guild lookup set by WithGuildMembership, nil falls back to PlayerInfo.GuildID
+func (ts *TeamSystem) SetGuildMembership(guilds GuildMembership) {
+	ts.guilds = guilds
+}
+
+// IsGuildMember reports whether guid belongs to the guild
+func (ts *TeamSystem) IsGuildMember(guildID, guid uint64) bool {
+	if guildID == kInvalidGuid {
+		return false
+	}
+	if ts.guilds != nil {
+		return ts.guilds.IsGuildMember(guildID, guid)
+	}
+	return ts.PlayerInfoOf(guid).GuildID == guildID
+}
+
+// guildMembers returns the players of guids that belong to the guild, in order. Without a
+// GuildMembership the players are looked up with one PlayerInfosOf call.
+func (ts *TeamSystem) guildMembers(guildID uint64, guids GuidVector) GuidVector {
+	members := make(GuidVector, 0, len(guids))
+	if guildID == kInvalidGuid {
+		return members
+	}
+	var infos map[uint64]PlayerInfo
+	if ts.guilds == nil {
+		infos = ts.PlayerInfosOf(guids)
+	}
+	for _, guid := range guids {
+		member := false
+		if ts.guilds != nil {
+			member = ts.guilds.IsGuildMember(guildID, guid)
+		} else {
+			member = infos[guid].GuildID == guildID
+		}
+		if member {
+			members = append(members, guid)
+		}
+	}
+	return members
+}
+
+// GuildOfTeam returns the guild owning the team, or kInvalidGuid for teams open to everyone
+func (ts *TeamSystem) GuildOfTeam(teamID uint64) uint64 {
+	if team, ok := ts.teams[teamID]; ok {
+		return team.GuildID
+	}
+	return kInvalidGuid
+}
+
+// TeamsOfGuild returns the teams owned by the guild in ID order
+func (ts *TeamSystem) TeamsOfGuild(guildID uint64) []uint64 {
+	teamIDs := make([]uint64, 0)
+	for teamID, team := range ts.teams {
+		if guildID != kInvalidGuid && team.GuildID == guildID {
+			teamIDs = append(teamIDs, teamID)
+		}
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	return teamIDs
+}
+
+// GuildMembersInTeams returns the members of the guild that are in any team, guild-owned or not
+func (ts *TeamSystem) GuildMembersInTeams(guildID uint64) GuidVector {
+	members := make(GuidVector, 0)
+	for _, team := range ts.teams {
+		members = append(members, team.MemberList.Guids()...)
+	}
+	guids := ts.guildMembers(guildID, members)
+	sort.Slice(guids, func(i, j int) bool { return guids[i] < guids[j] })
+	return guids
+}
+
+// checkGuild rejects players outside the guild of a guild-only team
+func (ts *TeamSystem) checkGuild(team *Team, guid uint64) uint32 {
+	if team.GuildID != kInvalidGuid && !ts.IsGuildMember(team.GuildID, guid) {
+		return kTeamNotInGuild
+	}
+	return kOK
+}
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuildOnlyTeam(t *testing.T) {
+	const guildID = 7
+	players := NewLocalPlayerInfoProvider()
+	for _, guid := range []uint64{100, 101, 102, 103, 104} {
+		players.Set(PlayerInfo{Guid: guid, GuildID: guildID})
+	}
+	ts := NewTeamSystem(WithPlayerInfoProvider(players, 0))
+
+	param := NewCreateTeamParam(100, []uint64{100, 200})
+	param.GuildID = guildID
+	if got := ts.CreateTeam(param); got != kTeamNotInGuild {
+		t.Errorf("CreateTeam() with an outsider = %v, want %v", got, kTeamNotInGuild)
+	}
+	param.MemberList = GuidVector{100, 101}
+	if got := ts.CreateTeam(param); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+	if got := ts.GuildOfTeam(teamID); got != guildID {
+		t.Errorf("GuildOfTeam() = %v, want %v", got, guildID)
+	}
+
+	for _, tt := range []struct {
+		name string
+		run  func() uint32
+		want uint32
+	}{
+		{"ApplyToTeam outsider", func() uint32 { return ts.ApplyToTeam(teamID, 200) }, kTeamNotInGuild},
+		{"JoinTeam outsider", func() uint32 { return ts.JoinTeam(teamID, 201) }, kTeamNotInGuild},
+		{"InviteToTeam outsider", func() uint32 { return ts.InviteToTeam(teamID, 100, 202) }, kTeamNotInGuild},
+		{"JoinTeamByMemberList with outsider", func() uint32 { return ts.JoinTeamByMemberList(GuidVector{103, 203}, teamID) }, kTeamNotInGuild},
+		{"ApplyToTeam member", func() uint32 { return ts.ApplyToTeam(teamID, 102) }, kOK},
+		{"InviteToTeam member", func() uint32 { return ts.InviteToTeam(teamID, 100, 103) }, kOK},
+	} {
+		if got := tt.run(); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if ts.HasTeam(203) || ts.MemberSize(teamID) != 3 {
+		t.Errorf("rejected member list joined partially, members = %v", ts.TeamMembers(teamID).Guids())
+	}
+
+	ts.CreateTeam(NewCreateTeamParam(104, []uint64{104, 300}))
+	if got, want := ts.TeamsOfGuild(guildID), []uint64{teamID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TeamsOfGuild() = %v, want %v", got, want)
+	}
+	lookups := players.Lookups()
+	if got, want := ts.GuildMembersInTeams(guildID), (GuidVector{100, 101, 103, 104}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GuildMembersInTeams() = %v, want %v", got, want)
+	}
+	if got := players.Lookups() - lookups; got != 1 {
+		t.Errorf("GuildMembersInTeams() provider lookups = %v, want %v", got, 1)
+	}
+	if got := ts.ListJoinableTeams(301); len(got) != 1 || got[0] == teamID {
+		t.Errorf("ListJoinableTeams() for an outsider = %v, want only the open team", got)
+	}
+}
+
+func TestGuildMembershipLookup(t *testing.T) {
+	ts := NewTeamSystem(WithGuildMembership(GuildMembershipFunc(func(guildID, guid uint64) bool {
+		return guid/100 == guildID
+	})))
+
+	param := NewCreateTeamParam(300, []uint64{300, 301})
+	param.GuildID = 3
+	if got := ts.CreateTeam(param); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.JoinTeam(ts.LastTeamID(), 401); got != kTeamNotInGuild {
+		t.Errorf("JoinTeam() outside the guild = %v, want %v", got, kTeamNotInGuild)
+	}
+
+	snapshot := ts.Snapshot()
+	restored := RestoreTeamSystem(snapshot)
+	if got := restored.GuildOfTeam(ts.LastTeamID()); got != 3 {
+		t.Errorf("GuildOfTeam() after restore = %v, want %v", got, 3)
+	}
+}
+
+func TestAutoAcceptGuildMembership(t *testing.T) {
+	ts := NewTeamSystem(WithGuildMembership(GuildMembershipFunc(func(guildID, guid uint64) bool {
+		return guildID == 9 && guid == 5
+	})))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+	ts.SetAutoAcceptRules(teamID, 100, []AutoAcceptRule{{GuildID: 9}})
+
+	if got := ts.ApplyToTeam(teamID, 5); got != kOK {
+		t.Errorf("ApplyToTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.GetTeamID(5); got != teamID {
+		t.Errorf("GetTeamID() of a guild member = %v, want %v", got, teamID)
+	}
+	ts.ApplyToTeam(teamID, 6)
+	if !ts.IsApplicant(teamID, 6) {
+		t.Errorf("IsApplicant() of a player outside the guild = false, want true")
+	}
+}
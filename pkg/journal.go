@@ -0,0 +1,315 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JournalEntry records one mutating call with its arguments and result.
+// The fields used by each Op mirror the arguments of the method, see ApplyJournalEntry.
+type JournalEntry struct {
+	Seq      uint64     `json:"seq"`
+	Time     time.Time  `json:"time"` // Clock of the recording system, replay runs the call at the same time
+	Op       Op         `json:"op"`
+	TeamID   uint64     `json:"team_id,omitempty"`
+	ActorID  uint64     `json:"actor_id,omitempty"`
+	TargetID uint64     `json:"target_id,omitempty"`
+	Members  GuidVector `json:"members,omitempty"`
+	TypeSize uint64     `json:"type_size,omitempty"`
+	GuildID  uint64     `json:"guild_id,omitempty"`
+	Version  *uint64    `json:"version,omitempty"` // Expected team version of KickMemberIfVersion and AppointLeaderIfVersion
+	Args     *OpArgs    `json:"args,omitempty"`
+	Schedule uint64     `json:"schedule_id,omitempty"` // Roster of the scheduled team calls
+	Result   uint32     `json:"result"`
+}
+
+// opJournal appends every mutating call to an optional writer as a JSON line
+type opJournal struct {
+	w       io.Writer
+	lastSeq uint64
+	err     error // First error returned by w
+}
+
+// WithJournal records every mutating call to w so it can be replayed by Replay
+func WithJournal(w io.Writer) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.journal.w = w
+	}
+}
+
+// JournalErr returns the first error the journal writer failed with
+func (ts *TeamSystem) JournalErr() error {
+	return ts.journal.err
+}
+
+func (j *opJournal) record(call opCall, result uint32) {
+	if j.w == nil || j.err != nil {
+		return
+	}
+	j.lastSeq++
+	entry := JournalEntry{
+		Seq:      j.lastSeq,
+		Time:     call.at,
+		Op:       call.op,
+		TeamID:   call.teamID,
+		ActorID:  call.actorID,
+		TargetID: call.targetID,
+		Members:  call.members,
+		TypeSize: call.typeSize,
+		GuildID:  call.guildID,
+		Version:  call.version,
+		Args:     call.args,
+		Schedule: call.schedule,
+		Result:   result,
+	}
+	if err := json.NewEncoder(j.w).Encode(entry); err != nil {
+		j.err = err
+	}
+}
+
+// ApplyJournalEntry performs the call recorded by entry and returns its result
+func (ts *TeamSystem) ApplyJournalEntry(entry JournalEntry) (uint32, error) {
+	args := entry.Args
+	if args == nil {
+		args = &OpArgs{}
+	}
+	switch entry.Op {
+	case OpCreateTeam:
+		return ts.CreateTeam(CreateTeamParam{LeaderID: entry.ActorID, MemberList: entry.Members, TeamTypeSize: entry.TypeSize, GuildID: entry.GuildID}), nil
+	case OpCreateRaid:
+		return ts.CreateRaid(CreateTeamParam{LeaderID: entry.ActorID, MemberList: entry.Members, GuildID: entry.GuildID}), nil
+	case OpJoinTeam:
+		return ts.JoinTeam(entry.TeamID, entry.TargetID), nil
+	case OpJoinTeamByMemberList:
+		return ts.JoinTeamByMemberList(entry.Members, entry.TeamID), nil
+	case OpInviteToTeam:
+		return ts.InviteToTeam(entry.TeamID, entry.ActorID, entry.TargetID), nil
+	case OpLeaveTeam:
+		return ts.LeaveTeam(entry.ActorID), nil
+	case OpKickMember:
+		if entry.Version != nil {
+			return ts.KickMemberIfVersion(entry.TeamID, entry.ActorID, entry.TargetID, *entry.Version), nil
+		}
+		return ts.KickMember(entry.TeamID, entry.ActorID, entry.TargetID), nil
+	case OpDisbanded:
+		return ts.Disbanded(entry.TeamID, entry.ActorID), nil
+	case OpDisbandedTeamNoLeader:
+		return ts.DisbandedTeamNoLeader(entry.TeamID), nil
+	case OpAppointLeader:
+		if entry.Version != nil {
+			return ts.AppointLeaderIfVersion(entry.TeamID, entry.ActorID, entry.TargetID, *entry.Version), nil
+		}
+		return ts.AppointLeader(entry.TeamID, entry.ActorID, entry.TargetID), nil
+	case OpApplyToTeam:
+		return ts.ApplyToTeam(entry.TeamID, entry.ActorID), nil
+	case OpDelApplicant:
+		return ts.DelApplicant(entry.TeamID, entry.TargetID), nil
+	case OpClearApplyList:
+		return ts.ClearApplyList(entry.TeamID), nil
+	case OpWithdrawApplication:
+		return ts.WithdrawApplication(entry.TeamID, entry.ActorID), nil
+	case OpSetMemberRole:
+		return ts.SetMemberRole(entry.TeamID, entry.ActorID, entry.TargetID, args.Role), nil
+	case OpSetMemberRank:
+		return ts.SetMemberRank(entry.TeamID, entry.ActorID, entry.TargetID, args.Rank), nil
+	case OpSetMemberAttribute:
+		return ts.SetMemberAttribute(entry.TeamID, entry.TargetID, args.Key, args.Value), nil
+	case OpSetRaidAssistant:
+		return ts.SetRaidAssistant(entry.TeamID, entry.ActorID, entry.TargetID, args.Assistant), nil
+	case OpMoveRaidMember:
+		return ts.MoveRaidMember(entry.TeamID, entry.ActorID, entry.TargetID, args.Group), nil
+	case OpSwapRaidMembers:
+		return ts.SwapRaidMembers(entry.TeamID, entry.ActorID, entry.TargetID, args.SecondID), nil
+	case OpSetLootMethod:
+		return ts.SetLootMethod(entry.TeamID, entry.ActorID, args.LootMethod, entry.TargetID), nil
+	case OpDistributeLoot:
+		_, result := ts.DistributeLoot(entry.TeamID, args.LootItems)
+		return result, nil
+	case OpEnterInstance:
+		if args.Instance == nil {
+			return kOK, fmt.Errorf("journal op %q without instance", entry.Op)
+		}
+		return ts.EnterInstance(entry.TeamID, args.Instance.ActivityID, args.Instance.InstanceID, args.Instance.ExpiresAt), nil
+	case OpLeaveInstance:
+		return ts.LeaveInstance(entry.TeamID), nil
+	case OpUpdateTeamSettings:
+		return ts.UpdateTeamSettings(entry.TeamID, entry.ActorID, args.SettingsVersion, func(settings *TeamSettings) {
+			if args.Settings != nil {
+				*settings = args.Settings.clone()
+			}
+		}), nil
+	case OpSetAutoAcceptRules:
+		return ts.SetAutoAcceptRules(entry.TeamID, entry.ActorID, args.Rules), nil
+	case OpScheduleTeam:
+		if args.StartAt == nil {
+			return kOK, fmt.Errorf("journal op %q without start time", entry.Op)
+		}
+		param := CreateTeamParam{LeaderID: entry.ActorID, MemberList: entry.Members, TeamTypeSize: entry.TypeSize, GuildID: entry.GuildID}
+		_, result := ts.ScheduleTeam(param, *args.StartAt)
+		return result, nil
+	case OpSignUp:
+		return ts.SignUp(entry.Schedule, entry.ActorID), nil
+	case OpCancelSignUp:
+		return ts.CancelSignUp(entry.Schedule, entry.ActorID), nil
+	case OpCancelSchedule:
+		return ts.CancelSchedule(entry.Schedule, entry.ActorID), nil
+	case OpActivateSchedule:
+		_, result := ts.ActivateSchedule(entry.Schedule)
+		return result, nil
+	}
+	return kOK, fmt.Errorf("unknown journal op %q", entry.Op)
+}
+
+// ReplayDivergence describes the first journal entry whose replay did not match the recording
+type ReplayDivergence struct {
+	Line     int
+	Entry    JournalEntry
+	Result   uint32 // Result of the replayed call
+	TeamID   uint64 // Team created by the replayed call, for CreateTeam, CreateRaid and ActivateSchedule
+	Schedule uint64 // Roster created by the replayed call, for ScheduleTeam
+}
+
+func (d ReplayDivergence) String() string {
+	if d.Result != d.Entry.Result {
+		return fmt.Sprintf("line %d seq %d %s: recorded result %d, replayed result %d",
+			d.Line, d.Entry.Seq, d.Entry.Op, d.Entry.Result, d.Result)
+	}
+	if d.Entry.Op == OpScheduleTeam {
+		return fmt.Sprintf("line %d seq %d %s: recorded schedule %d, replayed schedule %d",
+			d.Line, d.Entry.Seq, d.Entry.Op, d.Entry.Schedule, d.Schedule)
+	}
+	return fmt.Sprintf("line %d seq %d %s: recorded team %d, replayed team %d",
+		d.Line, d.Entry.Seq, d.Entry.Op, d.Entry.TeamID, d.TeamID)
+}
+
+// ReplayReport summarizes a replay
+type ReplayReport struct {
+	Entries    int               // Entries replayed, including the divergent one
+	Divergence *ReplayDivergence // Nil when every result matched
+}
+
+// Replay applies the journal read from r to ts and stops at the first divergence. Each call runs
+// with the clock of ts set to the recorded time, the clock is restored once Replay returns.
+// State outside the journaled calls, such as player lockouts, relations, player infos
+// or the loot seed, must be set up on ts beforehand, see NewTeamSystemFromConfig.
+// Chat messages are not journaled.
+func Replay(r io.Reader, ts *TeamSystem) (ReplayReport, error) {
+	clock := ts.clock
+	defer ts.SetClock(clock)
+
+	report := ReplayReport{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return report, fmt.Errorf("line %d: %w", line, err)
+		}
+		if at := entry.Time; !at.IsZero() {
+			ts.SetClock(func() time.Time { return at })
+		}
+		result, err := ts.ApplyJournalEntry(entry)
+		if err != nil {
+			return report, fmt.Errorf("line %d: %w", line, err)
+		}
+		report.Entries++
+
+		divergence := ReplayDivergence{Line: line, Entry: entry, Result: result}
+		switch entry.Op {
+		case OpCreateTeam, OpCreateRaid, OpActivateSchedule:
+			if result == kOK {
+				divergence.TeamID = ts.LastTeamID()
+			}
+			if divergence.TeamID != entry.TeamID {
+				report.Divergence = &divergence
+				return report, nil
+			}
+		case OpScheduleTeam:
+			if result == kOK {
+				divergence.Schedule = ts.lastScheduleID
+			}
+			if divergence.Schedule != entry.Schedule {
+				report.Divergence = &divergence
+				return report, nil
+			}
+		}
+		if result != entry.Result {
+			report.Divergence = &divergence
+			return report, nil
+		}
+	}
+	return report, scanner.Err()
+}
+
+// JournalConfig is the configuration of the recording system that its journal does not carry.
+// Replaying with the same configuration makes rate limits, application caps, the applicant
+// policy, auto-accept rules and loot rolls decide as they did when recording.
+type JournalConfig struct {
+	MaxApplications int                `json:"max_applications,omitempty"`
+	ApplicantPolicy ApplicantPolicy    `json:"applicant_policy,omitempty"` // ApplicantByScore uses DefaultApplicantScore
+	RateLimits      []JournalRateLimit `json:"rate_limits,omitempty"`
+	Players         []PlayerInfo       `json:"players,omitempty"`  // Served by a LocalPlayerInfoProvider
+	Blocks          [][2]uint64        `json:"blocks,omitempty"`   // The first player blocks the second
+	Friends         [][2]uint64        `json:"friends,omitempty"`  // The first player befriends the second
+	Lockouts        []PlayerLockout    `json:"lockouts,omitempty"` // Player lockouts set with AddPlayerLockout
+	LootSeed        *int64             `json:"loot_seed,omitempty"`
+}
+
+// JournalRateLimit is one SetRateLimits call of a JournalConfig
+type JournalRateLimit struct {
+	Op       Op         `json:"op"`
+	TypeSize uint64     `json:"type_size,omitempty"`
+	Limits   RateLimits `json:"limits"`
+}
+
+// PlayerLockout is a lockout of one player
+type PlayerLockout struct {
+	Guid   uint64        `json:"guid"`
+	Record LockoutRecord `json:"record"`
+}
+
+// NewTeamSystemFromConfig creates a TeamSystem configured like the system that recorded a journal,
+// opts are applied after the configuration
+func NewTeamSystemFromConfig(config JournalConfig, opts ...TeamSystemOption) (*TeamSystem, error) {
+	options := []TeamSystemOption{
+		WithMaxApplications(config.MaxApplications),
+		WithApplicantPolicy(config.ApplicantPolicy, nil),
+	}
+	if len(config.Players) > 0 {
+		players := NewLocalPlayerInfoProvider()
+		for _, info := range config.Players {
+			players.Set(info)
+		}
+		options = append(options, WithPlayerInfoProvider(players, 0))
+	}
+	ts := NewTeamSystem(append(options, opts...)...)
+
+	for _, limit := range config.RateLimits {
+		if !ts.SetRateLimits(limit.Op, limit.TypeSize, limit.Limits) {
+			return nil, fmt.Errorf("journal config: op %q cannot be rate limited", limit.Op)
+		}
+	}
+	if len(config.Blocks) > 0 || len(config.Friends) > 0 {
+		relations := NewLocalRelationProvider()
+		for _, pair := range config.Blocks {
+			relations.Block(pair[0], pair[1])
+		}
+		for _, pair := range config.Friends {
+			relations.AddFriend(pair[0], pair[1])
+		}
+		ts.SetRelationProvider(relations)
+	}
+	for _, lockout := range config.Lockouts {
+		ts.AddPlayerLockout(lockout.Guid, lockout.Record)
+	}
+	if config.LootSeed != nil {
+		ts.SetLootSeed(*config.LootSeed)
+	}
+	return ts, nil
+}
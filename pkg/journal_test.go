@@ -0,0 +1,237 @@
+package pkg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournalReplay(t *testing.T) {
+	var journal bytes.Buffer
+	ts := NewTeamSystem(WithJournal(&journal))
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	teamID := ts.LastTeamID()
+	ts.ApplyToTeam(teamID, 200)
+	ts.JoinTeam(teamID, 200)
+	ts.JoinTeamByMemberList(GuidVector{300, 301}, teamID)
+	ts.KickMember(teamID, 101, 100)
+	ts.AppointLeader(teamID, 100, 101)
+	ts.LeaveTeam(100)
+	ts.CreateRaid(NewCreateTeamParam(400, []uint64{400, 401}))
+	ts.Disbanded(teamID, 101)
+
+	if err := ts.JournalErr(); err != nil {
+		t.Fatalf("JournalErr() = %v, want nil", err)
+	}
+	if got := strings.Count(journal.String(), "\n"); got != 9 {
+		t.Fatalf("journal lines = %v, want %v\n%s", got, 9, journal.String())
+	}
+
+	report, err := Replay(bytes.NewReader(journal.Bytes()), NewTeamSystem())
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Entries != 9 || report.Divergence != nil {
+		t.Errorf("Replay() = %+v, want 9 entries without divergence", report)
+	}
+}
+
+func TestJournalReplayTeamState(t *testing.T) {
+	var journal bytes.Buffer
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ts := NewTeamSystem(WithJournal(&journal))
+	ts.SetClock(func() time.Time { return now })
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}))
+	teamID := ts.LastTeamID()
+	ts.SetMemberRole(teamID, 101, 101, RoleHealer)
+	ts.SetMemberRank(teamID, 100, 102, 3)
+	ts.SetMemberAttribute(teamID, 102, "spec", "frost")
+	ts.SetLootMethod(teamID, 100, LootNeedGreed, kInvalidGuid)
+	ts.DistributeLoot(teamID, []LootItem{{ItemID: 1, Rolls: map[uint64]LootRoll{101: LootNeed}}})
+	ts.EnterInstance(teamID, 7, 70, now.Add(time.Hour))
+	ts.LeaveInstance(teamID)
+	ts.UpdateTeamSettings(teamID, 100, 0, func(settings *TeamSettings) { settings.Description = "farm" })
+	ts.UpdateTeamSettings(teamID, 100, 0, func(settings *TeamSettings) { settings.Description = "stale" })
+	ts.SetAutoAcceptRules(teamID, 100, []AutoAcceptRule{{Roles: []MemberRole{RoleTank}}})
+	version, _ := ts.TeamVersion(teamID)
+	if got := ts.KickMemberIfVersion(teamID, 100, 102, version); got != kOK {
+		t.Fatalf("KickMemberIfVersion() = %v, want %v", got, kOK)
+	}
+
+	ts.CreateRaid(NewCreateTeamParam(200, []uint64{200, 201, 202, 203, 204, 205}))
+	raidID := ts.LastTeamID()
+	ts.SetRaidAssistant(raidID, 200, 201, true)
+	ts.MoveRaidMember(raidID, 201, 205, 2)
+	ts.SwapRaidMembers(raidID, 200, 202, 205)
+	ts.MoveRaidMember(raidID, 202, 203, 2)
+	version, _ = ts.TeamVersion(raidID)
+	if got := ts.AppointLeaderIfVersion(raidID, 200, 201, version); got != kOK {
+		t.Fatalf("AppointLeaderIfVersion() = %v, want %v", got, kOK)
+	}
+
+	replayed := NewTeamSystem()
+	replayed.SetClock(func() time.Time { return now })
+	report, err := Replay(bytes.NewReader(journal.Bytes()), replayed)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Entries != 18 || report.Divergence != nil {
+		t.Fatalf("Replay() = %+v, want 18 entries without divergence\n%s", report, journal.String())
+	}
+	for _, id := range []uint64{teamID, raidID} {
+		want, _ := ts.TeamSnapshot(id)
+		got, _ := replayed.TeamSnapshot(id)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("replayed TeamSnapshot(%v) = %+v, want %+v", id, got, want)
+		}
+	}
+	if got, want := replayed.RaidLayout(raidID), ts.RaidLayout(raidID); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed RaidLayout() = %v, want %v", got, want)
+	}
+}
+
+func TestJournalReplayDivergence(t *testing.T) {
+	var journal bytes.Buffer
+	ts := NewTeamSystem(WithJournal(&journal))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	ts.ApplyToTeam(ts.LastTeamID(), 200)
+
+	// Player 200 blocks the leader only on the replaying side
+	relations := NewLocalRelationProvider()
+	relations.Block(100, 200)
+	replayed := NewTeamSystem()
+	replayed.SetRelationProvider(relations)
+
+	report, err := Replay(bytes.NewReader(journal.Bytes()), replayed)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Divergence == nil {
+		t.Fatalf("Replay() divergence = nil, want ApplyToTeam on line 2")
+	}
+	if got := report.Divergence; got.Line != 2 || got.Entry.Op != OpApplyToTeam || got.Result != kTeamPlayerBlocked {
+		t.Errorf("Replay() divergence = %+v, want line 2 ApplyToTeam with result %v", got, kTeamPlayerBlocked)
+	}
+
+	if _, err := Replay(strings.NewReader(`{"seq":1,"op":"Teleport"}`), NewTeamSystem()); err == nil {
+		t.Errorf("Replay(unknown op) error = nil, want error")
+	}
+}
+
+func TestJournalReplayZeroVersion(t *testing.T) {
+	var journal bytes.Buffer
+	ts := NewTeamSystem(WithJournal(&journal))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	if got := ts.KickMemberIfVersion(ts.LastTeamID(), 100, 101, 0); got != kTeamVersionConflict {
+		t.Fatalf("KickMemberIfVersion() = %v, want %v", got, kTeamVersionConflict)
+	}
+	if got := ts.AppointLeaderIfVersion(ts.LastTeamID(), 100, 101, 0); got != kTeamVersionConflict {
+		t.Fatalf("AppointLeaderIfVersion() = %v, want %v", got, kTeamVersionConflict)
+	}
+
+	report, err := Replay(bytes.NewReader(journal.Bytes()), NewTeamSystem())
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Entries != 3 || report.Divergence != nil {
+		t.Errorf("Replay() = %+v, want 3 entries without divergence\n%s", report, journal.String())
+	}
+}
+
+func TestJournalReplaySchedules(t *testing.T) {
+	var journal bytes.Buffer
+	ts := NewTeamSystem(WithJournal(&journal))
+	startAt := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	scheduleID, _ := ts.ScheduleTeam(NewCreateTeamParam(100, []uint64{100, 101}, 3), startAt)
+	ts.SignUp(scheduleID, 102)
+	ts.SignUp(scheduleID, 103)
+	ts.CancelSignUp(scheduleID, 101)
+	ts.CancelSchedule(scheduleID, 102)
+	ts.ScheduleTeam(NewCreateTeamParam(200, []uint64{200}), startAt)
+	report, err := ts.ActivateSchedule(scheduleID)
+	if err != kOK {
+		t.Fatalf("ActivateSchedule() = %v, want %v", err, kOK)
+	}
+
+	if got := strings.Count(journal.String(), "\n"); got != 7 {
+		t.Fatalf("journal lines = %v, want %v\n%s", got, 7, journal.String())
+	}
+	replayed := NewTeamSystem()
+	replayReport, replayErr := Replay(bytes.NewReader(journal.Bytes()), replayed)
+	if replayErr != nil || replayReport.Divergence != nil {
+		t.Fatalf("Replay() = %+v, %v, want no divergence\n%s", replayReport, replayErr, journal.String())
+	}
+	if got := replayed.TeamMembers(report.TeamID).Guids(); !reflect.DeepEqual(got, GuidVector{100, 102, 103}) {
+		t.Errorf("replayed TeamMembers() = %v, want %v", got, GuidVector{100, 102, 103})
+	}
+	if _, ok := replayed.ScheduledTeamOf(scheduleID + 1); !ok {
+		t.Errorf("replayed ScheduledTeamOf() = false, want true")
+	}
+}
+
+func TestJournalReplayAfterExpiry(t *testing.T) {
+	config := JournalConfig{
+		RateLimits: []JournalRateLimit{{Op: OpApplyToTeam, Limits: RateLimits{Player: RateLimit{Rate: 1.0 / 60, Burst: 1}}}},
+		Lockouts:   []PlayerLockout{{Guid: 300, Record: LockoutRecord{ActivityID: 7, InstanceID: 72, ExpiresAt: time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC)}}},
+	}
+	var journal bytes.Buffer
+	ts, err := NewTeamSystemFromConfig(config, WithJournal(&journal))
+	if err != nil {
+		t.Fatalf("NewTeamSystemFromConfig() error = %v", err)
+	}
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	first := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}))
+	second := ts.LastTeamID()
+	ts.EnterInstance(first, 7, 70, now.Add(time.Hour))
+	ts.EnterInstance(second, 7, 71, now.Add(time.Hour))
+	ts.LeaveTeam(101)
+	// Both are still locked to another instance of the activity
+	if got := ts.JoinTeam(second, 101); got != kTeamLockoutConflict {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamLockoutConflict)
+	}
+	if got := ts.JoinTeam(second, 300); got != kTeamLockoutConflict {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamLockoutConflict)
+	}
+	ts.ApplyToTeam(first, 400)
+	if got := ts.ApplyToTeam(second, 400); got != kTeamRateLimited {
+		t.Errorf("ApplyToTeam() = %v, want %v", got, kTeamRateLimited)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := ts.ApplyToTeam(second, 400); got != kOK {
+		t.Errorf("ApplyToTeam() after refill = %v, want %v", got, kOK)
+	}
+
+	// The lockouts and the rate limit window expired long before the replay runs
+	replayed, err := NewTeamSystemFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewTeamSystemFromConfig() error = %v", err)
+	}
+	report, err := Replay(bytes.NewReader(journal.Bytes()), replayed)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Entries != 10 || report.Divergence != nil {
+		t.Errorf("Replay() = %+v, want 10 entries without divergence", report)
+	}
+	if got := replayed.now(); time.Since(got) > time.Minute {
+		t.Errorf("clock after Replay() = %v, want restored to time.Now", got)
+	}
+
+	// Without the configuration the lockout of 300 is missing
+	report, err = Replay(bytes.NewReader(journal.Bytes()), NewTeamSystem())
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Divergence == nil || report.Divergence.Entry.Op != OpJoinTeam || report.Divergence.Result != kOK {
+		t.Errorf("Replay() without config = %+v, want divergence on the JoinTeam of the locked player", report)
+	}
+}
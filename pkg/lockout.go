@@ -0,0 +1,168 @@
+package pkg
+
+import "time"
+
+// LockoutRecord binds a player or team to an instance of an activity until ExpiresAt
+type LockoutRecord struct {
+	ActivityID uint64
+	InstanceID uint64
+	ExpiresAt  time.Time
+}
+
+func (r LockoutRecord) expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// SetClock replaces the clock used for lockout expiry and timestamps, nil restores time.Now
+func (ts *TeamSystem) SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	ts.clock = clock
+}
+
+func (ts *TeamSystem) now() time.Time {
+	return ts.clock()
+}
+
+// AddPlayerLockout records a lockout of guid, replacing any record for the same activity
+func (ts *TeamSystem) AddPlayerLockout(guid uint64, record LockoutRecord) {
+	lockouts, ok := ts.playerLockouts[guid]
+	if !ok {
+		lockouts = make(map[uint64]LockoutRecord)
+		ts.playerLockouts[guid] = lockouts
+	}
+	lockouts[record.ActivityID] = record
+}
+
+// PlayerLockout returns the unexpired lockout of guid for the activity
+func (ts *TeamSystem) PlayerLockout(guid, activityID uint64) (LockoutRecord, bool) {
+	record, ok := ts.playerLockouts[guid][activityID]
+	if !ok || record.expired(ts.now()) {
+		return LockoutRecord{}, false
+	}
+	return record, true
+}
+
+// TeamLockout returns the unexpired lockout of the team for the activity
+func (ts *TeamSystem) TeamLockout(teamID, activityID uint64) (LockoutRecord, bool) {
+	record, ok := ts.teamLockouts[teamID][activityID]
+	if !ok || record.expired(ts.now()) {
+		return LockoutRecord{}, false
+	}
+	return record, true
+}
+
+// ActiveInstance returns the lockout of the instance the team is currently in
+func (ts *TeamSystem) ActiveInstance(teamID uint64) (LockoutRecord, bool) {
+	activityID, ok := ts.teamInstances[teamID]
+	if !ok {
+		return LockoutRecord{}, false
+	}
+	return ts.TeamLockout(teamID, activityID)
+}
+
+// EnterInstance marks the team as inside an instance and locks every member to it
+func (ts *TeamSystem) EnterInstance(teamID, activityID, instanceID uint64, expiresAt time.Time) uint32 {
+	call := ts.beginOp(OpEnterInstance, teamID, kInvalidGuid, kInvalidGuid)
+	call.args = &OpArgs{Instance: &LockoutRecord{ActivityID: activityID, InstanceID: instanceID, ExpiresAt: expiresAt}}
+	return ts.endOp(call, ts.enterInstance(teamID, activityID, instanceID, expiresAt))
+}
+
+func (ts *TeamSystem) enterInstance(teamID, activityID, instanceID uint64, expiresAt time.Time) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+
+	record := LockoutRecord{ActivityID: activityID, InstanceID: instanceID, ExpiresAt: expiresAt}
+	for _, member := range team.MemberList {
+		if ts.lockoutConflicts(member.Guid, record) {
+			return kTeamLockoutConflict
+		}
+	}
+
+	lockouts, ok := ts.teamLockouts[teamID]
+	if !ok {
+		lockouts = make(map[uint64]LockoutRecord)
+		ts.teamLockouts[teamID] = lockouts
+	}
+	lockouts[activityID] = record
+	ts.teamInstances[teamID] = activityID
+	for _, member := range team.MemberList {
+		ts.bindPlayerLockout(member.Guid, record)
+	}
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// LeaveInstance clears the active instance of the team, its lockout records are kept until expiry
+func (ts *TeamSystem) LeaveInstance(teamID uint64) uint32 {
+	call := ts.beginOp(OpLeaveInstance, teamID, kInvalidGuid, kInvalidGuid)
+	return ts.endOp(call, ts.leaveInstance(teamID))
+}
+
+func (ts *TeamSystem) leaveInstance(teamID uint64) uint32 {
+	if _, ok := ts.teams[teamID]; !ok {
+		return kTeamHasNotTeamId
+	}
+	delete(ts.teamInstances, teamID)
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// CheckLockout returns kTeamLockoutConflict when guid is locked to another instance
+// of the activity the team is currently in
+func (ts *TeamSystem) CheckLockout(teamID, guid uint64) uint32 {
+	if record, ok := ts.ActiveInstance(teamID); ok && ts.lockoutConflicts(guid, record) {
+		return kTeamLockoutConflict
+	}
+	return kOK
+}
+
+// PurgeExpiredLockouts drops every expired record and returns how many were removed
+func (ts *TeamSystem) PurgeExpiredLockouts() int {
+	now := ts.now()
+	removed := purgeLockouts(ts.playerLockouts, now)
+	removed += purgeLockouts(ts.teamLockouts, now)
+	for teamID, activityID := range ts.teamInstances {
+		if _, ok := ts.teamLockouts[teamID][activityID]; !ok {
+			delete(ts.teamInstances, teamID)
+		}
+	}
+	return removed
+}
+
+func purgeLockouts(lockouts map[uint64]map[uint64]LockoutRecord, now time.Time) int {
+	removed := 0
+	for id, records := range lockouts {
+		for activityID, record := range records {
+			if record.expired(now) {
+				delete(records, activityID)
+				removed++
+			}
+		}
+		if len(records) == 0 {
+			delete(lockouts, id)
+		}
+	}
+	return removed
+}
+
+func (ts *TeamSystem) lockoutConflicts(guid uint64, record LockoutRecord) bool {
+	current, ok := ts.PlayerLockout(guid, record.ActivityID)
+	return ok && current.InstanceID != record.InstanceID
+}
+
+// bindPlayerLockout saves guid to the instance unless already locked to it
+func (ts *TeamSystem) bindPlayerLockout(guid uint64, record LockoutRecord) {
+	if _, ok := ts.PlayerLockout(guid, record.ActivityID); !ok {
+		ts.AddPlayerLockout(guid, record)
+	}
+}
+
+func (ts *TeamSystem) lockoutMemberJoined(team *Team, guid uint64) {
+	if record, ok := ts.ActiveInstance(team.ID); ok {
+		ts.bindPlayerLockout(guid, record)
+	}
+}
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutBlocksJoin(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	activityID := uint64(7)
+	expiresAt := now.Add(time.Hour)
+
+	if got := ts.CreateTeam(NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	firstTeamID := ts.LastTeamID()
+	if got := ts.EnterInstance(firstTeamID, activityID, 1, expiresAt); got != kOK {
+		t.Errorf("EnterInstance() = %v, want %v", got, kOK)
+	}
+	if record, ok := ts.PlayerLockout(100, activityID); !ok || record.InstanceID != 1 {
+		t.Errorf("PlayerLockout() = %v %v, want instance 1", record, ok)
+	}
+
+	if got := ts.CreateTeam(NewCreateTeamParam(200, []uint64{200})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	secondTeamID := ts.LastTeamID()
+	if got := ts.EnterInstance(secondTeamID, activityID, 2, expiresAt); got != kOK {
+		t.Errorf("EnterInstance() = %v, want %v", got, kOK)
+	}
+
+	// 101 joins the first team mid-instance and is saved to instance 1
+	if got := ts.JoinTeam(firstTeamID, 101); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.LeaveTeam(101); got != kOK {
+		t.Errorf("LeaveTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.ApplyToTeam(secondTeamID, 101); got != kTeamLockoutConflict {
+		t.Errorf("ApplyToTeam() = %v, want %v", got, kTeamLockoutConflict)
+	}
+	if got := ts.JoinTeam(secondTeamID, 101); got != kTeamLockoutConflict {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamLockoutConflict)
+	}
+	if got := ts.JoinTeamByMemberList(GuidVector{102, 101}, secondTeamID); got != kTeamLockoutConflict {
+		t.Errorf("JoinTeamByMemberList() = %v, want %v", got, kTeamLockoutConflict)
+	}
+	if ts.HasTeam(102) {
+		t.Errorf("Expected 102 not to join when another member is locked out")
+	}
+	if got := ts.JoinTeam(firstTeamID, 101); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	ts.LeaveTeam(101)
+
+	if got := ts.LeaveInstance(secondTeamID); got != kOK {
+		t.Errorf("LeaveInstance() = %v, want %v", got, kOK)
+	}
+	if got := ts.JoinTeam(secondTeamID, 101); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+}
+
+func TestLockoutExpiry(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	activityID := uint64(7)
+	ts.AddPlayerLockout(101, LockoutRecord{ActivityID: activityID, InstanceID: 1, ExpiresAt: now.Add(time.Hour)})
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+	if got := ts.EnterInstance(teamID, activityID, 2, now.Add(2*time.Hour)); got != kOK {
+		t.Errorf("EnterInstance() = %v, want %v", got, kOK)
+	}
+	if got := ts.JoinTeam(teamID, 101); got != kTeamLockoutConflict {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamLockoutConflict)
+	}
+
+	now = now.Add(time.Hour)
+	if got := ts.JoinTeam(teamID, 101); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if record, _ := ts.PlayerLockout(101, activityID); record.InstanceID != 2 {
+		t.Errorf("PlayerLockout() instance = %v, want %v", record.InstanceID, 2)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := ts.ActiveInstance(teamID); ok {
+		t.Errorf("Expected active instance to expire")
+	}
+	if got := ts.PurgeExpiredLockouts(); got != 3 {
+		t.Errorf("PurgeExpiredLockouts() = %v, want %v", got, 3)
+	}
+	if got := len(ts.playerLockouts); got != 0 {
+		t.Errorf("player lockouts = %v, want %v", got, 0)
+	}
+	if got := len(ts.teamInstances); got != 0 {
+		t.Errorf("team instances = %v, want %v", got, 0)
+	}
+}
@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// TeamSystemOption configures a TeamSystem created by NewTeamSystem
+type TeamSystemOption func(*TeamSystem)
+
+// WithLogger logs every mutating operation to logger
+func WithLogger(logger *slog.Logger) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.log.logger = logger
+	}
+}
+
+// WithLogLevels sets the levels of successful and failed operations, by default Debug and Info
+func WithLogLevels(success, failure slog.Level) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.log.successLevel = success
+		ts.log.failureLevel = failure
+	}
+}
+
+// WithQuerySampling logs one of every n lookups such as GetTeamID at Debug level, 0 disables query logging
+func WithQuerySampling(n uint64) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.log.sampleEvery = n
+	}
+}
+
+// opLogger writes operation records to an optional slog.Logger
+type opLogger struct {
+	logger       *slog.Logger
+	successLevel slog.Level
+	failureLevel slog.Level
+	sampleEvery  uint64
+	queries      atomic.Uint64 // Lookups may run concurrently
+}
+
+func newOpLogger() *opLogger {
+	return &opLogger{successLevel: slog.LevelDebug, failureLevel: slog.LevelInfo}
+}
+
+func (l *opLogger) logOp(call opCall, result uint32) {
+	if l.logger == nil {
+		return
+	}
+	level := l.successLevel
+	if result != kOK {
+		level = l.failureLevel
+	}
+	if !l.logger.Enabled(context.Background(), level) {
+		return
+	}
+	l.logger.LogAttrs(context.Background(), level, "team operation",
+		slog.String("op", string(call.op)),
+		slog.Uint64("team_id", call.teamID),
+		slog.Uint64("actor_id", call.actorID),
+		slog.Uint64("target_id", call.targetID),
+		slog.Uint64("result", uint64(result)),
+		slog.Duration("duration", time.Since(call.start)),
+	)
+}
+
+func (l *opLogger) logQuery(query string, attrs ...slog.Attr) {
+	if l.logger == nil || l.sampleEvery == 0 {
+		return
+	}
+	if l.queries.Add(1)%l.sampleEvery != 0 {
+		return
+	}
+	attrs = append(attrs, slog.String("query", query), slog.Uint64("sample_every", l.sampleEvery))
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, "team query", attrs...)
+}
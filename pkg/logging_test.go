@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	records := make([]map[string]any, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Unmarshal(%q) error = %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestOperationLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	ts := NewTeamSystem(WithLogger(logger), WithLogLevels(slog.LevelInfo, slog.LevelWarn))
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	ts.JoinTeam(ts.LastTeamID(), 100)
+
+	records := decodeLogLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("logged %v records, want %v\n%s", len(records), 2, buf.String())
+	}
+	if records[0]["op"] != string(OpCreateTeam) || records[0]["level"] != "INFO" || records[0]["team_id"] != float64(1) {
+		t.Errorf("first record = %v, want CreateTeam at INFO for team 1", records[0])
+	}
+	if records[1]["op"] != string(OpJoinTeam) || records[1]["level"] != "WARN" || records[1]["result"] != float64(kTeamMemberInTeam) {
+		t.Errorf("second record = %v, want failed JoinTeam at WARN", records[1])
+	}
+	if records[1]["target_id"] != float64(100) {
+		t.Errorf("second record target_id = %v, want %v", records[1]["target_id"], 100)
+	}
+	if _, ok := records[1]["duration"]; !ok {
+		t.Errorf("second record = %v, want duration", records[1])
+	}
+}
+
+func TestQueryLoggingSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ts := NewTeamSystem(WithLogger(logger), WithQuerySampling(10))
+
+	for i := 0; i < 25; i++ {
+		ts.GetTeamID(100)
+	}
+	records := decodeLogLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("logged %v query records, want %v\n%s", len(records), 2, buf.String())
+	}
+	if records[0]["query"] != "GetTeamID" || records[0]["level"] != "DEBUG" {
+		t.Errorf("query record = %v, want GetTeamID at DEBUG", records[0])
+	}
+
+	// Without a logger nothing is recorded and nothing fails
+	ts = NewTeamSystem(WithQuerySampling(1))
+	ts.GetTeamID(100)
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+}
+
+func TestQueryLoggingConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ts := NewTeamSystem(WithLogger(logger), WithQuerySampling(10))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	buf.Reset()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				ts.GetTeamID(100)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(decodeLogLines(t, &buf)); got != 10 {
+		t.Errorf("logged %v query records, want %v", got, 10)
+	}
+}
@@ -0,0 +1,214 @@
+package pkg
+
+import "math/rand"
+
+// kDefaultLootSeed seeds the loot RNG until SetLootSeed is called
+const kDefaultLootSeed int64 = 1
+
+// kLootRollMax is the highest value of a need/greed roll
+const kLootRollMax = 100
+
+// LootMethod is the rule used to pick the recipient of each item
+type LootMethod uint8
+
+const (
+	LootFreeForAll LootMethod = iota
+	LootRoundRobin
+	LootNeedGreed
+	LootMasterLooter
+	lootMethodCount
+)
+
+// LootRoll is the need/greed declaration of a member for one item
+type LootRoll uint8
+
+const (
+	LootPass LootRoll = iota
+	LootGreed
+	LootNeed
+)
+
+// LootItem is one item to distribute. Rolls holds the need/greed declarations of the
+// members, a member without a declaration passes. Rolls is only used by LootNeedGreed.
+type LootItem struct {
+	ItemID uint64              `json:"item_id"`
+	Rolls  map[uint64]LootRoll `json:"rolls,omitempty"`
+}
+
+// LootAward records which member received an item
+type LootAward struct {
+	ItemID uint64
+	Winner uint64   // kInvalidGuid when every member passed on a need/greed item
+	Roll   uint32   // Winning roll for need/greed, zero otherwise
+	Choice LootRoll // Declaration the winner rolled with for need/greed
+}
+
+// teamLoot keeps the loot settings and round-robin rotation of a team
+type teamLoot struct {
+	method       LootMethod
+	masterLooter uint64
+	rotation     GuidVector // Members in join order
+	next         int        // Index in rotation of the next round-robin recipient
+}
+
+// SetLootSeed reseeds the loot RNG so that distributions can be reproduced
+func (ts *TeamSystem) SetLootSeed(seed int64) {
+	ts.lootRand = rand.New(rand.NewSource(seed))
+}
+
+// SetLootMethod changes the loot rule of the team, masterLooterID is only used by LootMasterLooter
+func (ts *TeamSystem) SetLootMethod(teamID, currentLeaderID uint64, method LootMethod, masterLooterID uint64) uint32 {
+	call := ts.beginOp(OpSetLootMethod, teamID, currentLeaderID, masterLooterID)
+	call.args = &OpArgs{LootMethod: method}
+	return ts.endOp(call, ts.setLootMethod(teamID, currentLeaderID, method, masterLooterID))
+}
+
+func (ts *TeamSystem) setLootMethod(teamID, currentLeaderID uint64, method LootMethod, masterLooterID uint64) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if team.LeaderID != currentLeaderID {
+		return kTeamLootNotLeader
+	}
+	if method >= lootMethodCount {
+		return kTeamLootMethod
+	}
+	if method == LootMasterLooter && !ts.HasMember(teamID, masterLooterID) {
+		return kTeamMemberNotInTeam
+	}
+
+	loot := ts.teamLoot(teamID)
+	loot.method = method
+	loot.masterLooter = kInvalidGuid
+	if method == LootMasterLooter {
+		loot.masterLooter = masterLooterID
+	}
+	team.Version++
+	return kOK
+}
+
+func (ts *TeamSystem) LootMethodOf(teamID uint64) LootMethod {
+	if loot, ok := ts.loots[teamID]; ok {
+		return loot.method
+	}
+	return LootFreeForAll
+}
+
+func (ts *TeamSystem) MasterLooter(teamID uint64) uint64 {
+	if loot, ok := ts.loots[teamID]; ok {
+		return loot.masterLooter
+	}
+	return kInvalidGuid
+}
+
+// DistributeLoot picks a recipient among the team members for each item
+func (ts *TeamSystem) DistributeLoot(teamID uint64, items []LootItem) ([]LootAward, uint32) {
+	call := ts.beginOp(OpDistributeLoot, teamID, kInvalidGuid, kInvalidGuid)
+	call.args = &OpArgs{LootItems: items}
+	awards, result := ts.distributeLoot(teamID, items)
+	return awards, ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) distributeLoot(teamID uint64, items []LootItem) ([]LootAward, uint32) {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return nil, kTeamHasNotTeamId
+	}
+	if len(team.MemberList) == 0 {
+		return nil, kTeamMemberNotInTeam
+	}
+
+	loot := ts.teamLoot(teamID)
+	awards := make([]LootAward, 0, len(items))
+	for _, item := range items {
+		award := LootAward{ItemID: item.ItemID}
+		switch loot.method {
+		case LootRoundRobin:
+			award.Winner = loot.nextRoundRobin()
+		case LootNeedGreed:
+			award.Winner, award.Roll, award.Choice = ts.rollLoot(team.MemberList, item.Rolls)
+		case LootMasterLooter:
+			award.Winner = ts.masterLooterOf(team, loot)
+		default:
+			award.Winner = team.MemberList[ts.lootRand.Intn(len(team.MemberList))].Guid
+		}
+		awards = append(awards, award)
+	}
+	return awards, kOK
+}
+
+// rollLoot rolls for the members declaring need, or for those declaring greed when nobody needs.
+// Ties go to the member listed first, declarations of non-members are ignored.
+func (ts *TeamSystem) rollLoot(members MemberList, rolls map[uint64]LootRoll) (uint64, uint32, LootRoll) {
+	for _, choice := range []LootRoll{LootNeed, LootGreed} {
+		winner, best := kInvalidGuid, uint32(0)
+		for _, member := range members {
+			if rolls[member.Guid] != choice {
+				continue
+			}
+			roll := uint32(ts.lootRand.Intn(kLootRollMax)) + 1
+			if roll > best {
+				winner, best = member.Guid, roll
+			}
+		}
+		if winner != kInvalidGuid {
+			return winner, best, choice
+		}
+	}
+	return kInvalidGuid, 0, LootPass
+}
+
+// masterLooterOf falls back to the leader, then the first member, once the master looter has left
+func (ts *TeamSystem) masterLooterOf(team *Team, loot *teamLoot) uint64 {
+	if ts.HasMember(team.ID, loot.masterLooter) {
+		return loot.masterLooter
+	}
+	if ts.HasMember(team.ID, team.LeaderID) {
+		return team.LeaderID
+	}
+	return team.MemberList[0].Guid
+}
+
+func (loot *teamLoot) nextRoundRobin() uint64 {
+	if loot.next >= len(loot.rotation) {
+		loot.next = 0
+	}
+	winner := loot.rotation[loot.next]
+	loot.next = (loot.next + 1) % len(loot.rotation)
+	return winner
+}
+
+func (ts *TeamSystem) teamLoot(teamID uint64) *teamLoot {
+	loot, ok := ts.loots[teamID]
+	if !ok {
+		loot = &teamLoot{}
+		ts.loots[teamID] = loot
+	}
+	return loot
+}
+
+func (ts *TeamSystem) lootMemberJoined(team *Team, guid uint64) {
+	loot := ts.teamLoot(team.ID)
+	loot.rotation = append(loot.rotation, guid)
+}
+
+// lootMemberRemoved keeps the round-robin pointer on the same next recipient
+func (ts *TeamSystem) lootMemberRemoved(team *Team, guid uint64) {
+	loot, ok := ts.loots[team.ID]
+	if !ok {
+		return
+	}
+	for idx, member := range loot.rotation {
+		if member == guid {
+			loot.rotation = append(loot.rotation[:idx], loot.rotation[idx+1:]...)
+			if idx < loot.next {
+				loot.next--
+			}
+			break
+		}
+	}
+	if loot.next >= len(loot.rotation) {
+		loot.next = 0
+	}
+}
@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLootRoundRobin(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(100)
+
+	if got := ts.CreateTeam(NewCreateTeamParam(leaderID, []uint64{100, 101, 102})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	if got := ts.SetLootMethod(teamID, 101, LootRoundRobin, kInvalidGuid); got != kTeamLootNotLeader {
+		t.Errorf("SetLootMethod() = %v, want %v", got, kTeamLootNotLeader)
+	}
+	if got := ts.SetLootMethod(teamID, leaderID, lootMethodCount, kInvalidGuid); got != kTeamLootMethod {
+		t.Errorf("SetLootMethod() = %v, want %v", got, kTeamLootMethod)
+	}
+	if got := ts.SetLootMethod(teamID, leaderID, LootRoundRobin, kInvalidGuid); got != kOK {
+		t.Errorf("SetLootMethod() = %v, want %v", got, kOK)
+	}
+
+	winners := func(items ...uint64) GuidVector {
+		awards, ret := ts.DistributeLoot(teamID, lootItems(items...))
+		if ret != kOK {
+			t.Errorf("DistributeLoot() = %v, want %v", ret, kOK)
+		}
+		result := make(GuidVector, 0, len(awards))
+		for _, award := range awards {
+			result = append(result, award.Winner)
+		}
+		return result
+	}
+
+	if got := winners(1, 2); !reflect.DeepEqual(got, GuidVector{100, 101}) {
+		t.Errorf("DistributeLoot() winners = %v, want %v", got, GuidVector{100, 101})
+	}
+
+	// 101 already received, leaving must not skip 102
+	if got := ts.LeaveTeam(101); got != kOK {
+		t.Errorf("LeaveTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.JoinTeam(teamID, 103); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if got := winners(3, 4, 5); !reflect.DeepEqual(got, GuidVector{102, 103, 100}) {
+		t.Errorf("DistributeLoot() winners = %v, want %v", got, GuidVector{102, 103, 100})
+	}
+}
+
+func TestLootSeededAndMasterLooter(t *testing.T) {
+	distribute := func(method LootMethod) []LootAward {
+		ts := NewTeamSystem()
+		ts.SetLootSeed(42)
+		ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102, 103, 104}))
+		if got := ts.SetLootMethod(ts.LastTeamID(), 100, method, kInvalidGuid); got != kOK {
+			t.Errorf("SetLootMethod() = %v, want %v", got, kOK)
+		}
+		items := lootItems(1, 2, 3, 4, 5, 6)
+		for _, item := range items {
+			for _, guid := range ts.TeamMembers(ts.LastTeamID()).Guids() {
+				item.Rolls[guid] = LootGreed
+			}
+		}
+		awards, _ := ts.DistributeLoot(ts.LastTeamID(), items)
+		return awards
+	}
+
+	for _, method := range []LootMethod{LootFreeForAll, LootNeedGreed} {
+		first, second := distribute(method), distribute(method)
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("DistributeLoot(%v) not reproducible: %v != %v", method, first, second)
+		}
+	}
+	for _, award := range distribute(LootNeedGreed) {
+		if award.Roll == 0 || award.Roll > kLootRollMax {
+			t.Errorf("need/greed roll = %v, want 1..%v", award.Roll, kLootRollMax)
+		}
+	}
+
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	teamID := ts.LastTeamID()
+	if got := ts.SetLootMethod(teamID, 100, LootMasterLooter, 999); got != kTeamMemberNotInTeam {
+		t.Errorf("SetLootMethod() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	if got := ts.SetLootMethod(teamID, 100, LootMasterLooter, 101); got != kOK {
+		t.Errorf("SetLootMethod() = %v, want %v", got, kOK)
+	}
+	awards, _ := ts.DistributeLoot(teamID, lootItems(1))
+	if awards[0].Winner != 101 {
+		t.Errorf("DistributeLoot() winner = %v, want %v", awards[0].Winner, 101)
+	}
+	ts.LeaveTeam(101)
+	awards, _ = ts.DistributeLoot(teamID, lootItems(1))
+	if awards[0].Winner != 100 {
+		t.Errorf("DistributeLoot() winner = %v, want %v", awards[0].Winner, 100)
+	}
+
+	if _, got := ts.DistributeLoot(999, lootItems(1)); got != kTeamHasNotTeamId {
+		t.Errorf("DistributeLoot() = %v, want %v", got, kTeamHasNotTeamId)
+	}
+}
+
+func TestLootNeedBeforeGreed(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102, 103}))
+	teamID := ts.LastTeamID()
+	ts.SetLootMethod(teamID, 100, LootNeedGreed, kInvalidGuid)
+
+	items := make([]LootItem, 0)
+	for seed := uint64(1); seed <= 20; seed++ {
+		items = append(items, LootItem{ItemID: seed, Rolls: map[uint64]LootRoll{100: LootGreed, 101: LootNeed, 102: LootGreed, 999: LootNeed}})
+	}
+	items = append(items,
+		LootItem{ItemID: 21, Rolls: map[uint64]LootRoll{100: LootGreed, 102: LootPass}},
+		LootItem{ItemID: 22, Rolls: map[uint64]LootRoll{999: LootNeed}},
+	)
+	awards, ret := ts.DistributeLoot(teamID, items)
+	if ret != kOK {
+		t.Fatalf("DistributeLoot() = %v, want %v", ret, kOK)
+	}
+	for _, award := range awards[:20] {
+		if award.Winner != 101 || award.Choice != LootNeed {
+			t.Errorf("DistributeLoot() item %v = %+v, want the only need roll of %v", award.ItemID, award, 101)
+		}
+	}
+	if got := awards[20]; got.Winner != 100 || got.Choice != LootGreed {
+		t.Errorf("DistributeLoot() greed only = %+v, want winner %v", got, 100)
+	}
+	if got := awards[21]; got.Winner != kInvalidGuid || got.Roll != 0 {
+		t.Errorf("DistributeLoot() all passed = %+v, want no winner", got)
+	}
+}
+
+func lootItems(ids ...uint64) []LootItem {
+	items := make([]LootItem, len(ids))
+	for idx, id := range ids {
+		items[idx] = LootItem{ItemID: id, Rolls: make(map[uint64]LootRoll)}
+	}
+	return items
+}
@@ -0,0 +1,182 @@
+package pkg
+
+import (
+	"sort"
+	"time"
+)
+
+// JoinMethod records how a member entered the team
+type JoinMethod uint8
+
+const (
+	JoinCreated      JoinMethod = iota // Listed when the team was created
+	JoinApplied                        // Accepted from the applicant list
+	JoinInvited                        // Invited by a member
+	JoinMatchmade                      // Added as part of a matchmade member list
+	JoinDirect                         // Added by JoinTeam without an application
+	JoinAutoAccepted                   // Applied and matched an auto-accept rule
+)
+
+// MemberRole is the combat role a member plays in the team
+type MemberRole uint8
+
+const (
+	RoleNone MemberRole = iota
+	RoleTank
+	RoleHealer
+	RoleDamage
+)
+
+// TeamMember represents one member of a team
+type TeamMember struct {
+	Guid       uint64            `json:"guid"`
+	JoinedAt   time.Time         `json:"joined_at"`
+	JoinMethod JoinMethod        `json:"join_method"`
+	Role       MemberRole        `json:"role"`
+	Rank       uint32            `json:"rank"`
+	Attributes map[string]string `json:"attributes,omitempty"` // Custom data owned by game modes
+}
+
+func (m TeamMember) clone() TeamMember {
+	if m.Attributes != nil {
+		attributes := make(map[string]string, len(m.Attributes))
+		for key, value := range m.Attributes {
+			attributes[key] = value
+		}
+		m.Attributes = attributes
+	}
+	return m
+}
+
+// MemberList is the list of members of a team in join order
+type MemberList []TeamMember
+
+// Guids returns the player IDs of the members
+func (l MemberList) Guids() GuidVector {
+	guids := make(GuidVector, len(l))
+	for idx, member := range l {
+		guids[idx] = member.Guid
+	}
+	return guids
+}
+
+// Index returns the position of guid in the list, or -1
+func (l MemberList) Index(guid uint64) int {
+	for idx, member := range l {
+		if member.Guid == guid {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (l MemberList) clone() MemberList {
+	members := make(MemberList, len(l))
+	for idx, member := range l {
+		members[idx] = member.clone()
+	}
+	return members
+}
+
+// TeamMembers returns a copy of the member records of the team
+func (ts *TeamSystem) TeamMembers(teamID uint64) MemberList {
+	if team, ok := ts.teams[teamID]; ok {
+		return team.MemberList.clone()
+	}
+	return nil
+}
+
+// TeamMemberOf returns a copy of the record of guid in the team
+func (ts *TeamSystem) TeamMemberOf(teamID, guid uint64) (TeamMember, bool) {
+	if team, ok := ts.teams[teamID]; ok {
+		if idx := team.MemberList.Index(guid); idx != -1 {
+			return team.MemberList[idx].clone(), true
+		}
+	}
+	return TeamMember{}, false
+}
+
+// MembersByTenure returns the member records ordered from the longest serving member
+func (ts *TeamSystem) MembersByTenure(teamID uint64) MemberList {
+	members := ts.TeamMembers(teamID)
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members
+}
+
+// SetMemberRole changes the role of guid, either the member or the leader may change it
+func (ts *TeamSystem) SetMemberRole(teamID, operatorID, guid uint64, role MemberRole) uint32 {
+	call := ts.beginOp(OpSetMemberRole, teamID, operatorID, guid)
+	call.args = &OpArgs{Role: role}
+	return ts.endOp(call, ts.setMemberRole(teamID, operatorID, guid, role))
+}
+
+func (ts *TeamSystem) setMemberRole(teamID, operatorID, guid uint64, role MemberRole) uint32 {
+	member, err := ts.editableMember(teamID, guid)
+	if err != kOK {
+		return err
+	}
+	if operatorID != guid && ts.teams[teamID].LeaderID != operatorID {
+		return kTeamAppointNotLeader
+	}
+	member.Role = role
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// SetMemberRank changes the rank of guid, only the leader may change it
+func (ts *TeamSystem) SetMemberRank(teamID, operatorID, guid uint64, rank uint32) uint32 {
+	call := ts.beginOp(OpSetMemberRank, teamID, operatorID, guid)
+	call.args = &OpArgs{Rank: rank}
+	return ts.endOp(call, ts.setMemberRank(teamID, operatorID, guid, rank))
+}
+
+func (ts *TeamSystem) setMemberRank(teamID, operatorID, guid uint64, rank uint32) uint32 {
+	member, err := ts.editableMember(teamID, guid)
+	if err != kOK {
+		return err
+	}
+	if ts.teams[teamID].LeaderID != operatorID {
+		return kTeamAppointNotLeader
+	}
+	member.Rank = rank
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// SetMemberAttribute stores a custom attribute on the member record, an empty value deletes it
+func (ts *TeamSystem) SetMemberAttribute(teamID, guid uint64, key, value string) uint32 {
+	call := ts.beginOp(OpSetMemberAttribute, teamID, kInvalidGuid, guid)
+	call.args = &OpArgs{Key: key, Value: value}
+	return ts.endOp(call, ts.setMemberAttribute(teamID, guid, key, value))
+}
+
+func (ts *TeamSystem) setMemberAttribute(teamID, guid uint64, key, value string) uint32 {
+	member, err := ts.editableMember(teamID, guid)
+	if err != kOK {
+		return err
+	}
+	if value == "" {
+		delete(member.Attributes, key)
+	} else {
+		if member.Attributes == nil {
+			member.Attributes = make(map[string]string)
+		}
+		member.Attributes[key] = value
+	}
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+func (ts *TeamSystem) editableMember(teamID, guid uint64) (*TeamMember, uint32) {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return nil, kTeamHasNotTeamId
+	}
+	idx := team.MemberList.Index(guid)
+	if idx == -1 {
+		return nil, kTeamMemberNotInTeam
+	}
+	return &team.MemberList[idx], kOK
+}
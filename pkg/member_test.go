@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemberJoinMethodsAndTenure(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	if got := ts.CreateTeam(NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	now = now.Add(time.Minute)
+	ts.ApplyToTeam(teamID, 101)
+	if got := ts.JoinTeam(teamID, 101); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	now = now.Add(time.Minute)
+	if got := ts.InviteToTeam(teamID, 100, 102); got != kOK {
+		t.Errorf("InviteToTeam() = %v, want %v", got, kOK)
+	}
+	now = now.Add(time.Minute)
+	if got := ts.JoinTeamByMemberList(GuidVector{103}, teamID); got != kOK {
+		t.Errorf("JoinTeamByMemberList() = %v, want %v", got, kOK)
+	}
+
+	wantMethods := map[uint64]JoinMethod{100: JoinCreated, 101: JoinApplied, 102: JoinInvited, 103: JoinMatchmade}
+	for guid, want := range wantMethods {
+		member, ok := ts.TeamMemberOf(teamID, guid)
+		if !ok || member.JoinMethod != want {
+			t.Errorf("TeamMemberOf(%v) = %v %v, want join method %v", guid, member, ok, want)
+		}
+	}
+	if member, _ := ts.TeamMemberOf(teamID, 102); !member.JoinedAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("TeamMemberOf() joined at %v, want %v", member.JoinedAt, now.Add(-time.Minute))
+	}
+
+	// 100 leaves and joins again, becoming the newest member
+	ts.AppointLeader(teamID, 100, 101)
+	ts.LeaveTeam(100)
+	now = now.Add(time.Minute)
+	ts.JoinTeam(teamID, 100)
+
+	if got := ts.MembersByTenure(teamID).Guids(); !reflect.DeepEqual(got, GuidVector{101, 102, 103, 100}) {
+		t.Errorf("MembersByTenure() = %v, want %v", got, GuidVector{101, 102, 103, 100})
+	}
+	if got := ts.MemberSize(teamID); got != 4 {
+		t.Errorf("MemberSize() = %v, want %v", got, 4)
+	}
+}
+
+func TestSetMemberRoleRankAttribute(t *testing.T) {
+	ts := NewTeamSystem()
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}))
+	teamID := ts.LastTeamID()
+
+	if got := ts.SetMemberRole(teamID, 101, 101, RoleHealer); got != kOK {
+		t.Errorf("SetMemberRole() = %v, want %v", got, kOK)
+	}
+	if got := ts.SetMemberRole(teamID, 102, 101, RoleTank); got != kTeamAppointNotLeader {
+		t.Errorf("SetMemberRole() = %v, want %v", got, kTeamAppointNotLeader)
+	}
+	if got := ts.SetMemberRole(teamID, 100, 102, RoleTank); got != kOK {
+		t.Errorf("SetMemberRole() = %v, want %v", got, kOK)
+	}
+	if got := ts.SetMemberRank(teamID, 101, 102, 3); got != kTeamAppointNotLeader {
+		t.Errorf("SetMemberRank() = %v, want %v", got, kTeamAppointNotLeader)
+	}
+	if got := ts.SetMemberRank(teamID, 100, 102, 3); got != kOK {
+		t.Errorf("SetMemberRank() = %v, want %v", got, kOK)
+	}
+	if got := ts.SetMemberAttribute(teamID, 999, "spec", "holy"); got != kTeamMemberNotInTeam {
+		t.Errorf("SetMemberAttribute() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	if got := ts.SetMemberAttribute(teamID, 101, "spec", "holy"); got != kOK {
+		t.Errorf("SetMemberAttribute() = %v, want %v", got, kOK)
+	}
+
+	healer, _ := ts.TeamMemberOf(teamID, 101)
+	if healer.Role != RoleHealer || healer.Attributes["spec"] != "holy" {
+		t.Errorf("TeamMemberOf() = %v, want healer with spec holy", healer)
+	}
+	healer.Attributes["spec"] = "shadow"
+	if healer, _ = ts.TeamMemberOf(teamID, 101); healer.Attributes["spec"] != "holy" {
+		t.Errorf("Expected TeamMemberOf() to return a copy")
+	}
+	tank, _ := ts.TeamMemberOf(teamID, 102)
+	if tank.Role != RoleTank || tank.Rank != 3 {
+		t.Errorf("TeamMemberOf() = %v, want tank with rank 3", tank)
+	}
+
+	ts.SetMemberAttribute(teamID, 101, "spec", "")
+	if healer, _ = ts.TeamMemberOf(teamID, 101); len(healer.Attributes) != 0 {
+		t.Errorf("Expected attribute to be deleted, got %v", healer.Attributes)
+	}
+}
@@ -0,0 +1,232 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// kTeamLifetimeBuckets are the upper bounds in seconds of the team lifetime histogram
+var kTeamLifetimeBuckets = []float64{60, 300, 900, 1800, 3600, 7200, 14400, 43200, 86400}
+
+type teamSizeKey struct {
+	typeSize uint64
+	members  int
+}
+
+type opFailureKey struct {
+	op   Op
+	code uint32
+}
+
+type rateLimitedKey struct {
+	op    Op
+	scope string
+}
+
+// teamMetrics is updated by the goroutine owning the TeamSystem and read by the metrics handler,
+// so it only holds values copied from the system under its own lock
+type teamMetrics struct {
+	mu             sync.Mutex
+	teams          int
+	players        int
+	applicants     int
+	teamApplicants map[uint64]int // Map of team ID to applicant count, zero counts are not kept
+	teamSizes      map[teamSizeKey]int
+	ops            map[Op]uint64
+	failures       map[opFailureKey]uint64
+	limited        map[rateLimitedKey]uint64
+	lifetimes      []uint64 // Count per bucket of kTeamLifetimeBuckets plus +Inf, not cumulative
+	lifetimeCount  uint64
+	lifetimeSum    float64
+}
+
+func newTeamMetrics() *teamMetrics {
+	return &teamMetrics{
+		teamApplicants: make(map[uint64]int),
+		teamSizes:      make(map[teamSizeKey]int),
+		ops:            make(map[Op]uint64),
+		failures:       make(map[opFailureKey]uint64),
+		limited:        make(map[rateLimitedKey]uint64),
+		lifetimes:      make([]uint64, len(kTeamLifetimeBuckets)+1),
+	}
+}
+
+func (m *teamMetrics) observeOp(op Op, result uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ops[op]++
+	if result != kOK {
+		m.failures[opFailureKey{op: op, code: result}]++
+	}
+}
+
+func (m *teamMetrics) rateLimited(op Op, scope string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limited[rateLimitedKey{op: op, scope: scope}]++
+}
+
+func (m *teamMetrics) setApplicants(teamID uint64, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.applicants += count - m.teamApplicants[teamID]
+	if count == 0 {
+		delete(m.teamApplicants, teamID)
+	} else {
+		m.teamApplicants[teamID] = count
+	}
+}
+
+func (m *teamMetrics) teamCreated(team *Team) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.teams++
+	m.moveTeamSize(team.TeamTypeSize, -1, len(team.MemberList))
+}
+
+func (m *teamMetrics) memberJoined(team *Team) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.players++
+	m.moveTeamSize(team.TeamTypeSize, len(team.MemberList)-1, len(team.MemberList))
+}
+
+func (m *teamMetrics) memberRemoved(team *Team) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.players--
+	m.moveTeamSize(team.TeamTypeSize, len(team.MemberList)+1, len(team.MemberList))
+}
+
+// teamErased also accounts for the members the erased team still listed
+func (m *teamMetrics) teamErased(team *Team, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.teams--
+	m.players -= len(team.MemberList)
+	m.moveTeamSize(team.TeamTypeSize, len(team.MemberList), -1)
+	m.applicants -= m.teamApplicants[team.ID]
+	delete(m.teamApplicants, team.ID)
+
+	lifetime := now.Sub(team.CreatedAt).Seconds()
+	bucket := sort.SearchFloat64s(kTeamLifetimeBuckets, lifetime)
+	m.lifetimes[bucket]++
+	m.lifetimeCount++
+	m.lifetimeSum += lifetime
+}
+
+// moveTeamSize moves one team between member counts, -1 meaning no count
+func (m *teamMetrics) moveTeamSize(typeSize uint64, from, to int) {
+	if from >= 0 {
+		key := teamSizeKey{typeSize: typeSize, members: from}
+		if m.teamSizes[key]--; m.teamSizes[key] <= 0 {
+			delete(m.teamSizes, key)
+		}
+	}
+	if to >= 0 {
+		m.teamSizes[teamSizeKey{typeSize: typeSize, members: to}]++
+	}
+}
+
+// WriteMetrics writes the team metrics in the Prometheus text exposition format
+func (ts *TeamSystem) WriteMetrics(w io.Writer) error {
+	var buf bytes.Buffer
+	ts.metrics.write(&buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// MetricsHandler serves WriteMetrics over HTTP, it may run concurrently with the TeamSystem owner
+func (ts *TeamSystem) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		ts.WriteMetrics(w)
+	})
+}
+
+func (m *teamMetrics) write(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeMetricHeader(buf, "team_active_teams", "gauge", "Number of teams in the system.")
+	fmt.Fprintf(buf, "team_active_teams %d\n", m.teams)
+	writeMetricHeader(buf, "team_players", "gauge", "Number of players in a team.")
+	fmt.Fprintf(buf, "team_players %d\n", m.players)
+	writeMetricHeader(buf, "team_applicants", "gauge", "Number of pending applications over all teams.")
+	fmt.Fprintf(buf, "team_applicants %d\n", m.applicants)
+
+	writeMetricHeader(buf, "team_operations_total", "counter", "Mutating operations by name.")
+	ops := make([]Op, 0, len(m.ops))
+	for op := range m.ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	for _, op := range ops {
+		fmt.Fprintf(buf, "team_operations_total{op=%q} %d\n", op, m.ops[op])
+	}
+
+	writeMetricHeader(buf, "team_operation_failures_total", "counter", "Failed mutating operations by name and result code.")
+	failures := make([]opFailureKey, 0, len(m.failures))
+	for key := range m.failures {
+		failures = append(failures, key)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].op != failures[j].op {
+			return failures[i].op < failures[j].op
+		}
+		return failures[i].code < failures[j].code
+	})
+	for _, key := range failures {
+		fmt.Fprintf(buf, "team_operation_failures_total{op=%q,code=\"%d\"} %d\n", key.op, key.code, m.failures[key])
+	}
+
+	writeMetricHeader(buf, "team_rate_limited_total", "counter", "Operations rejected by rate limits by name and limit scope.")
+	limited := make([]rateLimitedKey, 0, len(m.limited))
+	for key := range m.limited {
+		limited = append(limited, key)
+	}
+	sort.Slice(limited, func(i, j int) bool {
+		if limited[i].op != limited[j].op {
+			return limited[i].op < limited[j].op
+		}
+		return limited[i].scope < limited[j].scope
+	})
+	for _, key := range limited {
+		fmt.Fprintf(buf, "team_rate_limited_total{op=%q,scope=%q} %d\n", key.op, key.scope, m.limited[key])
+	}
+
+	writeMetricHeader(buf, "team_lifetime_seconds", "histogram", "Lifetime of erased teams.")
+	cumulative := uint64(0)
+	for i, bound := range kTeamLifetimeBuckets {
+		cumulative += m.lifetimes[i]
+		fmt.Fprintf(buf, "team_lifetime_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(buf, "team_lifetime_seconds_bucket{le=\"+Inf\"} %d\n", m.lifetimeCount)
+	fmt.Fprintf(buf, "team_lifetime_seconds_sum %s\n", strconv.FormatFloat(m.lifetimeSum, 'g', -1, 64))
+	fmt.Fprintf(buf, "team_lifetime_seconds_count %d\n", m.lifetimeCount)
+
+	writeMetricHeader(buf, "team_size_teams", "gauge", "Number of teams by team type size and member count.")
+	sizes := make([]teamSizeKey, 0, len(m.teamSizes))
+	for key := range m.teamSizes {
+		sizes = append(sizes, key)
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].typeSize != sizes[j].typeSize {
+			return sizes[i].typeSize < sizes[j].typeSize
+		}
+		return sizes[i].members < sizes[j].members
+	})
+	for _, key := range sizes {
+		fmt.Fprintf(buf, "team_size_teams{type_size=\"%d\",members=\"%d\"} %d\n", key.typeSize, key.members, m.teamSizes[key])
+	}
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, kind, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
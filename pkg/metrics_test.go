@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsOutput(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	firstTeamID := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}, kTenMemberMaxSize))
+	ts.CreateTeam(NewCreateTeamParam(300, []uint64{300}))
+	ts.CreateTeam(NewCreateTeamParam(300, []uint64{300}))
+	ts.ApplyToTeam(firstTeamID, 500)
+	ts.ApplyToTeam(firstTeamID, 501)
+	ts.JoinTeam(firstTeamID, 500)
+	ts.KickMember(firstTeamID, 101, 100)
+
+	now = now.Add(10 * time.Minute)
+	ts.LeaveTeam(300)
+
+	rec := httptest.NewRecorder()
+	ts.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %v, want Prometheus text format", got)
+	}
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE team_active_teams gauge",
+		"team_active_teams 2",
+		"team_players 4",
+		"team_applicants 1",
+		`team_operations_total{op="CreateTeam"} 4`,
+		`team_operations_total{op="JoinTeam"} 1`,
+		`team_operation_failures_total{op="CreateTeam",code="5003"} 1`,
+		`team_operation_failures_total{op="KickMember",code="5006"} 1`,
+		`team_lifetime_seconds_bucket{le="300"} 0`,
+		`team_lifetime_seconds_bucket{le="900"} 1`,
+		`team_lifetime_seconds_bucket{le="+Inf"} 1`,
+		"team_lifetime_seconds_sum 600",
+		"team_lifetime_seconds_count 1",
+		`team_size_teams{type_size="5",members="3"} 1`,
+		`team_size_teams{type_size="10",members="1"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics output missing %q\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `members="0"`) {
+		t.Errorf("metrics output contains empty team sizes\n%s", body)
+	}
+}
+
+func TestMetricsAfterDisband(t *testing.T) {
+	ts := NewTeamSystem()
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}))
+	ts.ApplyToTeam(ts.LastTeamID(), 500)
+	ts.Disbanded(ts.LastTeamID(), 100)
+
+	var buf strings.Builder
+	if err := ts.WriteMetrics(&buf); err != nil {
+		t.Errorf("WriteMetrics() error = %v", err)
+	}
+	for _, line := range []string{"team_active_teams 0", "team_players 0", "team_applicants 0"} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics output missing %q\n%s", line, buf.String())
+		}
+	}
+	if got := ts.PlayersSize(); got != 0 {
+		t.Errorf("PlayersSize() = %v, want %v", got, 0)
+	}
+}
@@ -0,0 +1,460 @@
+package pkg
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Model-based tests: random call sequences run against TeamSystem and against
+// teamModel, a plain reference implementation of the team rules. After every
+// call the result codes, the state and the invariants of TeamSystem are checked.
+
+type modelOpKind uint8
+
+const (
+	modelCreate modelOpKind = iota
+	modelJoin
+	modelApply
+	modelLeave
+	modelKick
+	modelAppoint
+	modelDisband
+	modelOpKindCount
+)
+
+var modelOpNames = [...]string{"Create", "Join", "Apply", "Leave", "Kick", "Appoint", "Disband"}
+
+// modelOp is one generated call, the meaning of A, B and C follows the method arguments
+type modelOp struct {
+	Kind    modelOpKind
+	A, B, C uint64
+	Members GuidVector // Members of a created team, the leader is A
+}
+
+func (op modelOp) String() string {
+	switch op.Kind {
+	case modelCreate:
+		return fmt.Sprintf("CreateTeam(leader %d, members %v, size %d)", op.A, op.Members, op.B)
+	case modelJoin, modelApply:
+		return fmt.Sprintf("%s(team %d, player %d)", modelOpNames[op.Kind], op.A, op.B)
+	case modelLeave:
+		return fmt.Sprintf("LeaveTeam(player %d)", op.A)
+	case modelDisband:
+		return fmt.Sprintf("Disbanded(team %d, leader %d)", op.A, op.B)
+	}
+	return fmt.Sprintf("%s(team %d, leader %d, player %d)", modelOpNames[op.Kind], op.A, op.B, op.C)
+}
+
+type modelTeam struct {
+	leader     uint64
+	members    GuidVector
+	applicants GuidVector
+	size       uint64
+}
+
+type teamModel struct {
+	teams  map[uint64]*modelTeam
+	index  map[uint64]uint64 // Player ID to team ID
+	lastID uint64
+}
+
+func newTeamModel() *teamModel {
+	return &teamModel{teams: make(map[uint64]*modelTeam), index: make(map[uint64]uint64)}
+}
+
+func removeGuid(list GuidVector, guid uint64) GuidVector {
+	for idx, other := range list {
+		if other == guid {
+			return append(list[:idx:idx], list[idx+1:]...)
+		}
+	}
+	return list
+}
+
+func containsGuid(list GuidVector, guid uint64) bool {
+	for _, other := range list {
+		if other == guid {
+			return true
+		}
+	}
+	return false
+}
+
+// joined withdraws every application of a player who is now in a team
+func (m *teamModel) joined(guid uint64) {
+	for _, team := range m.teams {
+		team.applicants = removeGuid(team.applicants, guid)
+	}
+}
+
+func (m *teamModel) apply(op modelOp) uint32 {
+	switch op.Kind {
+	case modelCreate:
+		if len(m.teams) >= kMaxTeamSize {
+			return kTeamListMaxSize
+		}
+		if _, ok := m.index[op.A]; ok {
+			return kTeamMemberInTeam
+		}
+		if len(op.Members) > int(op.B) {
+			return kTeamCreateTeamMaxMemberSize
+		}
+		for _, guid := range op.Members {
+			if _, ok := m.index[guid]; ok {
+				return kTeamMemberInTeam
+			}
+		}
+		m.lastID++
+		m.teams[m.lastID] = &modelTeam{leader: op.A, members: append(GuidVector{}, op.Members...), applicants: GuidVector{}, size: op.B}
+		for _, guid := range op.Members {
+			m.index[guid] = m.lastID
+			m.joined(guid)
+		}
+		return kOK
+
+	case modelJoin:
+		team, ok := m.teams[op.A]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		if _, ok := m.index[op.B]; ok {
+			return kTeamMemberInTeam
+		}
+		if len(team.members) >= int(team.size) {
+			return kTeamMembersFull
+		}
+		team.members = append(team.members, op.B)
+		m.index[op.B] = op.A
+		m.joined(op.B)
+		return kOK
+
+	case modelApply:
+		team, ok := m.teams[op.A]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		if _, ok := m.index[op.B]; ok {
+			return kTeamMemberInTeam
+		}
+		if len(team.members) >= int(team.size) {
+			return kTeamMembersFull
+		}
+		if containsGuid(team.applicants, op.B) {
+			return kTeamApplyJoin
+		}
+		if len(team.applicants) >= kMaxApplicantSize {
+			team.applicants = team.applicants[1:]
+		}
+		team.applicants = append(team.applicants, op.B)
+		return kOK
+
+	case modelLeave:
+		teamID := m.index[op.A]
+		team, ok := m.teams[teamID]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		team.members = removeGuid(team.members, op.A)
+		delete(m.index, op.A)
+		if len(team.members) == 0 {
+			delete(m.teams, teamID)
+		} else if team.leader == op.A {
+			team.leader = team.members[0]
+		}
+		return kOK
+
+	case modelKick:
+		team, ok := m.teams[op.A]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		if team.leader != op.B {
+			return kTeamKickNotLeader
+		}
+		if op.B == op.C {
+			return kTeamKickSelf
+		}
+		if !containsGuid(team.members, op.C) {
+			return kTeamMemberNotInTeam
+		}
+		team.members = removeGuid(team.members, op.C)
+		delete(m.index, op.C)
+		return kOK
+
+	case modelAppoint:
+		team, ok := m.teams[op.A]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		if team.leader == op.C {
+			return kTeamAppointSelf
+		}
+		if team.leader != op.B {
+			return kTeamAppointNotLeader
+		}
+		if !containsGuid(team.members, op.C) {
+			return kTeamMemberNotInTeam
+		}
+		team.leader = op.C
+		return kOK
+
+	case modelDisband:
+		team, ok := m.teams[op.A]
+		if !ok {
+			return kTeamHasNotTeamId
+		}
+		if team.leader != op.B {
+			return kTeamDismissNotLeader
+		}
+		for _, guid := range team.members {
+			delete(m.index, guid)
+		}
+		delete(m.teams, op.A)
+		return kOK
+	}
+	panic("unknown model op")
+}
+
+func applyModelOp(ts *TeamSystem, op modelOp) uint32 {
+	switch op.Kind {
+	case modelCreate:
+		return ts.CreateTeam(CreateTeamParam{LeaderID: op.A, MemberList: append(GuidVector{}, op.Members...), TeamTypeSize: op.B})
+	case modelJoin:
+		return ts.JoinTeam(op.A, op.B)
+	case modelApply:
+		return ts.ApplyToTeam(op.A, op.B)
+	case modelLeave:
+		return ts.LeaveTeam(op.A)
+	case modelKick:
+		return ts.KickMember(op.A, op.B, op.C)
+	case modelAppoint:
+		return ts.AppointLeader(op.A, op.B, op.C)
+	case modelDisband:
+		return ts.Disbanded(op.A, op.B)
+	}
+	panic("unknown model op")
+}
+
+// checkTeamInvariants returns a description of the first broken invariant of ts, or ""
+func checkTeamInvariants(ts *TeamSystem) string {
+	var broken string
+	ts.playerLists.Range(func(key, value interface{}) bool {
+		guid, teamID := key.(uint64), value.(uint64)
+		if !ts.HasMember(teamID, guid) {
+			broken = fmt.Sprintf("player %d is indexed to team %d but not a member", guid, teamID)
+			return false
+		}
+		return true
+	})
+	if broken != "" {
+		return broken
+	}
+
+	for teamID, team := range ts.teams {
+		seen := make(map[uint64]bool, len(team.MemberList))
+		for _, member := range team.MemberList {
+			if seen[member.Guid] {
+				return fmt.Sprintf("player %d is listed twice in team %d", member.Guid, teamID)
+			}
+			seen[member.Guid] = true
+			if got := ts.teamIDOf(member.Guid); got != teamID {
+				return fmt.Sprintf("member %d of team %d is indexed to team %d", member.Guid, teamID, got)
+			}
+		}
+		if len(team.MemberList) == 0 {
+			return fmt.Sprintf("team %d has no members", teamID)
+		}
+		if !seen[team.LeaderID] {
+			return fmt.Sprintf("leader %d of team %d is not a member", team.LeaderID, teamID)
+		}
+		if len(team.MemberList) > int(team.TeamTypeSize) {
+			return fmt.Sprintf("team %d has %d members over its size %d", teamID, len(team.MemberList), team.TeamTypeSize)
+		}
+		if len(team.Applicants) > kMaxApplicantSize {
+			return fmt.Sprintf("team %d has %d applicants over the cap %d", teamID, len(team.Applicants), kMaxApplicantSize)
+		}
+		applied := make(map[uint64]bool, len(team.Applicants))
+		for _, guid := range team.Applicants {
+			if applied[guid] || ts.HasTeam(guid) {
+				return fmt.Sprintf("applicant %d of team %d is duplicated or already in a team", guid, teamID)
+			}
+			applied[guid] = true
+			if !containsGuid(ts.applications[guid], teamID) {
+				return fmt.Sprintf("application of %d to team %d is missing from the index", guid, teamID)
+			}
+		}
+	}
+	for guid, teamIDs := range ts.applications {
+		for _, teamID := range teamIDs {
+			if !ts.IsApplicant(teamID, guid) {
+				return fmt.Sprintf("index lists an application of %d to team %d that does not exist", guid, teamID)
+			}
+		}
+	}
+	return ""
+}
+
+// compareWithModel returns a description of the first difference between ts and m, or ""
+func compareWithModel(ts *TeamSystem, m *teamModel) string {
+	if len(ts.teams) != len(m.teams) || ts.lastTeamID != m.lastID {
+		return fmt.Sprintf("%d teams up to id %d, model has %d up to id %d", len(ts.teams), ts.lastTeamID, len(m.teams), m.lastID)
+	}
+	for teamID, want := range m.teams {
+		team, ok := ts.teams[teamID]
+		if !ok {
+			return fmt.Sprintf("team %d is missing", teamID)
+		}
+		if team.LeaderID != want.leader || !reflect.DeepEqual(team.MemberList.Guids(), want.members) ||
+			!reflect.DeepEqual(append(GuidVector{}, team.Applicants...), want.applicants) {
+			return fmt.Sprintf("team %d has leader %d, members %v, applicants %v; model has leader %d, members %v, applicants %v",
+				teamID, team.LeaderID, team.MemberList.Guids(), team.Applicants, want.leader, want.members, want.applicants)
+		}
+	}
+	return ""
+}
+
+// runModel runs ops and returns a description of the first failure, or ""
+func runModel(ops []modelOp) string {
+	ts := NewTeamSystem()
+	m := newTeamModel()
+	for idx, op := range ops {
+		got, want := applyModelOp(ts, op), m.apply(op)
+		if got != want {
+			return fmt.Sprintf("step %d %v = %d, model returned %d", idx, op, got, want)
+		}
+		if broken := checkTeamInvariants(ts); broken != "" {
+			return fmt.Sprintf("step %d %v: %s", idx, op, broken)
+		}
+		if diff := compareWithModel(ts, m); diff != "" {
+			return fmt.Sprintf("step %d %v: %s", idx, op, diff)
+		}
+	}
+	return ""
+}
+
+// shrinkOps removes calls from a failing sequence while it keeps failing
+func shrinkOps(ops []modelOp, fails func([]modelOp) bool) []modelOp {
+	for chunk := len(ops) / 2; chunk > 0; chunk /= 2 {
+		for start := 0; start+chunk <= len(ops); {
+			candidate := append(append([]modelOp{}, ops[:start]...), ops[start+chunk:]...)
+			if fails(candidate) {
+				ops = candidate
+			} else {
+				start += chunk
+			}
+		}
+	}
+	return ops
+}
+
+func formatModelOps(ops []modelOp) string {
+	lines := make([]string, 0, len(ops))
+	for _, op := range ops {
+		lines = append(lines, "\t"+op.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkModelOps fails t with a shrunk sequence if ops break the model
+func checkModelOps(t *testing.T, ops []modelOp) {
+	t.Helper()
+	if runModel(ops) == "" {
+		return
+	}
+	shrunk := shrinkOps(ops, func(candidate []modelOp) bool { return runModel(candidate) != "" })
+	t.Fatalf("%s\nshrunk from %d to %d calls:\n%s", runModel(shrunk), len(ops), len(shrunk), formatModelOps(shrunk))
+}
+
+const (
+	kModelPlayers = 12 // Small pool so generated calls collide often
+	kModelTeams   = 6
+)
+
+// modelOpFromBytes builds a call from four bytes, shared by the random generator and the fuzz target
+func modelOpFromBytes(kind, a, b, c byte) modelOp {
+	player := func(v byte) uint64 { return uint64(v)%kModelPlayers + 1 }
+	op := modelOp{Kind: modelOpKind(kind) % modelOpKindCount}
+	switch op.Kind {
+	case modelCreate:
+		op.A = player(a)
+		op.B = []uint64{2, 3, kFiveMemberMaxSize}[c%3]
+		op.Members = GuidVector{op.A}
+		for _, guid := range []uint64{player(b), player(b >> 4), player(c >> 2)} {
+			if uint64(len(op.Members)) < op.B && !containsGuid(op.Members, guid) {
+				op.Members = append(op.Members, guid)
+			}
+		}
+	case modelJoin, modelApply:
+		op.A, op.B = uint64(a)%kModelTeams+1, player(b)
+	case modelLeave:
+		op.A = player(a)
+	case modelDisband:
+		op.A, op.B = uint64(a)%kModelTeams+1, player(b)
+	default:
+		op.A, op.B, op.C = uint64(a)%kModelTeams+1, player(b), player(c)
+	}
+	return op
+}
+
+func modelOpsFromBytes(data []byte) []modelOp {
+	ops := make([]modelOp, 0, len(data)/4)
+	for ; len(data) >= 4; data = data[4:] {
+		ops = append(ops, modelOpFromBytes(data[0], data[1], data[2], data[3]))
+	}
+	return ops
+}
+
+func TestModelRandomSequences(t *testing.T) {
+	for seed := int64(1); seed <= 300; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		data := make([]byte, 4*150)
+		rng.Read(data)
+		checkModelOps(t, modelOpsFromBytes(data))
+	}
+}
+
+func TestModelApplicantCap(t *testing.T) {
+	ops := []modelOp{{Kind: modelCreate, A: 1, B: 5, Members: GuidVector{1}}}
+	for guid := uint64(100); guid < 100+kMaxApplicantSize+5; guid++ {
+		ops = append(ops, modelOp{Kind: modelApply, A: 1, B: guid})
+	}
+	ops = append(ops, modelOp{Kind: modelJoin, A: 1, B: 100}, modelOp{Kind: modelJoin, A: 1, B: 110})
+	checkModelOps(t, ops)
+}
+
+func TestShrinkOps(t *testing.T) {
+	ops := make([]modelOp, 0, 40)
+	for i := uint64(0); i < 40; i++ {
+		ops = append(ops, modelOp{Kind: modelLeave, A: i})
+	}
+	// The sequence fails whenever it still contains both player 7 and player 23
+	fails := func(candidate []modelOp) bool {
+		found := 0
+		for _, op := range candidate {
+			if op.A == 7 || op.A == 23 {
+				found++
+			}
+		}
+		return found == 2
+	}
+	got := shrinkOps(ops, fails)
+	want := []modelOp{{Kind: modelLeave, A: 7}, {Kind: modelLeave, A: 23}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shrinkOps() = %v, want %v", got, want)
+	}
+}
+
+func FuzzTeamSystem(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 2, 2, 0, 5, 0, 1, 0, 5, 0, 3, 1, 0, 0})
+	f.Add([]byte{0, 1, 0x32, 1, 4, 0, 1, 3, 5, 0, 3, 0, 6, 0, 3, 0})
+	f.Add([]byte{0, 1, 0, 2, 0, 2, 0, 2, 2, 0, 7, 0, 1, 0, 7, 0, 6, 0, 1, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > 4*500 {
+			return
+		}
+		checkModelOps(t, modelOpsFromBytes(data))
+	})
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import "time"
+
+// Op names a mutating operation of TeamSystem
+type Op string
+
+const (
+	OpCreateTeam            Op = "CreateTeam"
+	OpCreateRaid            Op = "CreateRaid"
+	OpJoinTeam              Op = "JoinTeam"
+	OpJoinTeamByMemberList  Op = "JoinTeamByMemberList"
+	OpInviteToTeam          Op = "InviteToTeam"
+	OpLeaveTeam             Op = "LeaveTeam"
+	OpKickMember            Op = "KickMember"
+	OpDisbanded             Op = "Disbanded"
+	OpDisbandedTeamNoLeader Op = "DisbandedTeamNoLeader"
+	OpAppointLeader         Op = "AppointLeader"
+	OpApplyToTeam           Op = "ApplyToTeam"
+	OpDelApplicant          Op = "DelApplicant"
+	OpClearApplyList        Op = "ClearApplyList"
+	OpWithdrawApplication   Op = "WithdrawApplication"
+	OpSetMemberRole         Op = "SetMemberRole"
+	OpSetMemberRank         Op = "SetMemberRank"
+	OpSetMemberAttribute    Op = "SetMemberAttribute"
+	OpSetRaidAssistant      Op = "SetRaidAssistant"
+	OpMoveRaidMember        Op = "MoveRaidMember"
+	OpSwapRaidMembers       Op = "SwapRaidMembers"
+	OpSetLootMethod         Op = "SetLootMethod"
+	OpDistributeLoot        Op = "DistributeLoot"
+	OpEnterInstance         Op = "EnterInstance"
+	OpLeaveInstance         Op = "LeaveInstance"
+	OpUpdateTeamSettings    Op = "UpdateTeamSettings"
+	OpSetAutoAcceptRules    Op = "SetAutoAcceptRules"
+	OpScheduleTeam          Op = "ScheduleTeam"
+	OpSignUp                Op = "SignUp"
+	OpCancelSignUp          Op = "CancelSignUp"
+	OpCancelSchedule        Op = "CancelSchedule"
+	OpActivateSchedule      Op = "ActivateSchedule"
+)
+
+// audited reports whether the operation is administrative and kept in the audit log
+func (op Op) audited() bool {
+	switch op {
+	case OpKickMember, OpAppointLeader, OpDisbanded, OpDisbandedTeamNoLeader, OpClearApplyList, OpDelApplicant:
+		return true
+	}
+	return false
+}
+
+// opCall describes one mutating call while it runs.
+// actorID is the player issuing the call and targetID the player it applies to,
+// either is kInvalidGuid when the call has none.
+type opCall struct {
+	op       Op
+	teamID   uint64
+	actorID  uint64
+	targetID uint64
+	members  GuidVector // Member list of CreateTeam, CreateRaid, JoinTeamByMemberList and ScheduleTeam
+	typeSize uint64     // TeamTypeSize of CreateTeam and ScheduleTeam
+	guildID  uint64     // GuildID of CreateTeam, CreateRaid and ScheduleTeam
+	version  *uint64    // Expected team version of the conditional mutators, nil when unconditional
+	args     *OpArgs    // Arguments of the calls that do not fit the fields above
+	schedule uint64     // Roster of the scheduled team calls, created by ScheduleTeam
+	at       time.Time  // Clock of the system when the call began, journaled for replay
+	start    time.Time
+}
+
+// OpArgs holds the arguments specific to the member, raid, loot, instance, settings,
+// auto-accept and schedule calls. Each call sets only the fields matching its own arguments.
+type OpArgs struct {
+	Role            MemberRole       `json:"role,omitempty"`
+	Rank            uint32           `json:"rank,omitempty"`
+	Key             string           `json:"key,omitempty"`
+	Value           string           `json:"value,omitempty"`
+	Assistant       bool             `json:"assistant,omitempty"`
+	Group           int              `json:"group,omitempty"`
+	SecondID        uint64           `json:"second_id,omitempty"` // Second member of SwapRaidMembers
+	LootMethod      LootMethod       `json:"loot_method,omitempty"`
+	LootItems       []LootItem       `json:"loot_items,omitempty"`
+	Instance        *LockoutRecord   `json:"instance,omitempty"`
+	SettingsVersion uint64           `json:"settings_version,omitempty"`
+	Settings        *TeamSettings    `json:"settings,omitempty"` // Settings after a successful update
+	Rules           []AutoAcceptRule `json:"rules,omitempty"`
+	StartAt         *time.Time       `json:"start_at,omitempty"` // Start time of ScheduleTeam
+}
+
+// beginOp must be paired with endOp by every public mutating method.
+// Internal callers use the unexported implementations so nested calls are observed once.
+func (ts *TeamSystem) beginOp(op Op, teamID, actorID, targetID uint64) opCall {
+	return opCall{op: op, teamID: teamID, actorID: actorID, targetID: targetID, at: ts.now(), start: time.Now()}
+}
+
+func (ts *TeamSystem) endOp(call opCall, result uint32) uint32 {
+	ts.metrics.observeOp(call.op, result)
+	ts.metrics.setApplicants(call.teamID, ts.ApplicantSizeByTeamID(call.teamID))
+	if call.op.audited() {
+		ts.recordAudit(call, result)
+	}
+	ts.log.logOp(call, result)
+	ts.journal.record(call, result)
+	return result
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import "time"
+
+// PlayerInfo is what team rules know about a player
+type PlayerInfo struct {
+	Guid    uint64
+	Level   uint32
+	Role    MemberRole // Role the player queues as
+	GuildID uint64     // kInvalidGuid when the player has no guild
+}
+
+// PlayerInfoProvider looks up player attributes, typically from the player service
+type PlayerInfoProvider interface {
+	// PlayerInfos returns the known players among guids, unknown players are left out.
+	// Players of a failed lookup are treated as unknown and not cached.
+	PlayerInfos(guids GuidVector) (map[uint64]PlayerInfo, error)
+}
+
+// PlayerInfoFunc adapts a single player lookup to PlayerInfoProvider
+type PlayerInfoFunc func(guid uint64) (PlayerInfo, bool)
+
+func (f PlayerInfoFunc) PlayerInfos(guids GuidVector) (map[uint64]PlayerInfo, error) {
+	infos := make(map[uint64]PlayerInfo, len(guids))
+	for _, guid := range guids {
+		if info, ok := f(guid); ok {
+			infos[guid] = info
+		}
+	}
+	return infos, nil
+}
+
+// LocalPlayerInfoProvider is an in-memory PlayerInfoProvider
+type LocalPlayerInfoProvider struct {
+	players map[uint64]PlayerInfo
+	lookups int
+}
+
+func NewLocalPlayerInfoProvider() *LocalPlayerInfoProvider {
+	return &LocalPlayerInfoProvider{players: make(map[uint64]PlayerInfo)}
+}
+
+func (p *LocalPlayerInfoProvider) Set(info PlayerInfo) {
+	p.players[info.Guid] = info
+}
+
+func (p *LocalPlayerInfoProvider) Delete(guid uint64) {
+	delete(p.players, guid)
+}
+
+// Lookups returns how many times PlayerInfos was called
+func (p *LocalPlayerInfoProvider) Lookups() int {
+	return p.lookups
+}
+
+func (p *LocalPlayerInfoProvider) PlayerInfos(guids GuidVector) (map[uint64]PlayerInfo, error) {
+	p.lookups++
+	infos := make(map[uint64]PlayerInfo, len(guids))
+	for _, guid := range guids {
+		if info, ok := p.players[guid]; ok {
+			infos[guid] = info
+		}
+	}
+	return infos, nil
+}
+
+type playerInfoEntry struct {
+	info      PlayerInfo
+	expiresAt time.Time
+}
+
+// playerInfoCache keeps provider answers, unknown players included, for ttl
+type playerInfoCache struct {
+	provider PlayerInfoProvider
+	ttl      time.Duration
+	entries  map[uint64]playerInfoEntry
+}
+
+// WithPlayerInfoProvider looks up the player attributes needed by applicant scoring, auto-accept
+// rules and guild checks with provider and caches its answers for ttl, 0 disables caching.
+// Unknown players are treated as level 0 without role or guild.
+func WithPlayerInfoProvider(provider PlayerInfoProvider, ttl time.Duration) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.SetPlayerInfoProvider(provider, ttl)
+	}
+}
+
+// SetPlayerInfoProvider replaces the provider set by WithPlayerInfoProvider and empties the cache
+func (ts *TeamSystem) SetPlayerInfoProvider(provider PlayerInfoProvider, ttl time.Duration) {
+	ts.playerInfo = &playerInfoCache{provider: provider, ttl: ttl, entries: make(map[uint64]playerInfoEntry)}
+}
+
+// InvalidatePlayerInfo drops the cached attributes of guid, for example after a level up
+func (ts *TeamSystem) InvalidatePlayerInfo(guid uint64) {
+	delete(ts.playerInfo.entries, guid)
+}
+
+// PurgePlayerInfoCache drops every expired cache entry and returns how many were removed
+func (ts *TeamSystem) PurgePlayerInfoCache() int {
+	now := ts.now()
+	removed := 0
+	for guid, entry := range ts.playerInfo.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(ts.playerInfo.entries, guid)
+			removed++
+		}
+	}
+	return removed
+}
+
+// PlayerInfoOf returns the attributes of guid
+func (ts *TeamSystem) PlayerInfoOf(guid uint64) PlayerInfo {
+	return ts.PlayerInfosOf(GuidVector{guid})[guid]
+}
+
+// PlayerInfosOf returns the attributes of every player in guids with one provider lookup at most
+func (ts *TeamSystem) PlayerInfosOf(guids GuidVector) map[uint64]PlayerInfo {
+	cache := ts.playerInfo
+	now := ts.now()
+	infos := make(map[uint64]PlayerInfo, len(guids))
+	missing := make(GuidVector, 0, len(guids))
+	for _, guid := range guids {
+		if entry, ok := cache.entries[guid]; ok && now.Before(entry.expiresAt) {
+			infos[guid] = entry.info
+		} else if _, seen := infos[guid]; !seen {
+			infos[guid] = PlayerInfo{Guid: guid}
+			missing = append(missing, guid)
+		}
+	}
+	if len(missing) == 0 || cache.provider == nil {
+		return infos
+	}
+
+	found, err := cache.provider.PlayerInfos(missing)
+	if err != nil {
+		return infos
+	}
+	for _, guid := range missing {
+		info := found[guid]
+		info.Guid = guid
+		infos[guid] = info
+		if cache.ttl > 0 {
+			cache.entries[guid] = playerInfoEntry{info: info, expiresAt: now.Add(cache.ttl)}
+		}
+	}
+	return infos
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingPlayerInfoProvider struct{}
+
+func (failingPlayerInfoProvider) PlayerInfos(GuidVector) (map[uint64]PlayerInfo, error) {
+	return nil, errors.New("player service unavailable")
+}
+
+func TestPlayerInfoCache(t *testing.T) {
+	players := NewLocalPlayerInfoProvider()
+	players.Set(PlayerInfo{Guid: 100, Level: 50})
+	players.Set(PlayerInfo{Guid: 101, Level: 60, GuildID: 7})
+	ts := NewTeamSystem(WithPlayerInfoProvider(players, time.Minute))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+
+	infos := ts.PlayerInfosOf(GuidVector{100, 101, 102, 100})
+	if infos[100].Level != 50 || infos[101].GuildID != 7 || infos[102] != (PlayerInfo{Guid: 102}) {
+		t.Errorf("PlayerInfosOf() = %v, want levels 50 and 60 and unknown 102", infos)
+	}
+	if got := players.Lookups(); got != 1 {
+		t.Errorf("Lookups() after a batch = %v, want %v", got, 1)
+	}
+
+	// Cached answers, unknown players included, are served until the TTL passes
+	players.Set(PlayerInfo{Guid: 100, Level: 51})
+	ts.PlayerInfosOf(GuidVector{100, 102})
+	if got := ts.PlayerInfoOf(100).Level; got != 50 || players.Lookups() != 1 {
+		t.Errorf("PlayerInfoOf() = level %v after %v lookups, want cached level 50", got, players.Lookups())
+	}
+	ts.InvalidatePlayerInfo(100)
+	if got := ts.PlayerInfoOf(100).Level; got != 51 {
+		t.Errorf("PlayerInfoOf() after invalidation = level %v, want %v", got, 51)
+	}
+
+	now = now.Add(time.Minute)
+	if got := ts.PurgePlayerInfoCache(); got != 3 {
+		t.Errorf("PurgePlayerInfoCache() = %v, want %v", got, 3)
+	}
+	ts.PlayerInfoOf(101)
+	if got := players.Lookups(); got != 3 {
+		t.Errorf("Lookups() after expiry = %v, want %v", got, 3)
+	}
+}
+
+func TestPlayerInfoProviderFailure(t *testing.T) {
+	ts := NewTeamSystem()
+	if got := ts.PlayerInfoOf(100); got != (PlayerInfo{Guid: 100}) {
+		t.Errorf("PlayerInfoOf() without provider = %v, want unknown player", got)
+	}
+
+	ts.SetPlayerInfoProvider(failingPlayerInfoProvider{}, time.Minute)
+	if got := ts.PlayerInfoOf(100); got != (PlayerInfo{Guid: 100}) {
+		t.Errorf("PlayerInfoOf() with a failing provider = %v, want unknown player", got)
+	}
+	if got := ts.PurgePlayerInfoCache(); got != 0 {
+		t.Errorf("failed lookups were cached, PurgePlayerInfoCache() = %v", got)
+	}
+}
@@ -0,0 +1,246 @@
+package pkg
+
+// Raid constants
+const (
+	kRaidSubGroupSize  = 5
+	kRaidSubGroupCount = 8
+	kRaidMaxSize       = kRaidSubGroupSize * kRaidSubGroupCount
+)
+
+// Raid represents the sub-group layout of a raid team
+type Raid struct {
+	TeamID     uint64
+	SubGroups  [kRaidSubGroupCount]GuidVector
+	Assistants GuidVector
+}
+
+// CreateRaid creates a team sized for a raid and places its members into sub-groups
+func (ts *TeamSystem) CreateRaid(param CreateTeamParam) uint32 {
+	call := ts.beginOp(OpCreateRaid, kInvalidGuid, param.LeaderID, kInvalidGuid)
+	call.members, call.guildID = param.MemberList, param.GuildID
+	result := ts.createRaid(param)
+	if result == kOK {
+		call.teamID = ts.lastTeamID
+	}
+	return ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) createRaid(param CreateTeamParam) uint32 {
+	param.TeamTypeSize = kRaidMaxSize
+	if err := ts.createTeam(param); err != kOK {
+		return err
+	}
+
+	team := ts.teams[ts.lastTeamID]
+	ts.raids[team.ID] = &Raid{TeamID: team.ID}
+	for _, member := range team.MemberList {
+		ts.raidMemberJoined(team, member.Guid)
+	}
+	return kOK
+}
+
+func (ts *TeamSystem) IsRaid(teamID uint64) bool {
+	_, ok := ts.raids[teamID]
+	return ok
+}
+
+func (ts *TeamSystem) IsRaidAssistant(teamID, guid uint64) bool {
+	if raid, ok := ts.raids[teamID]; ok {
+		return raid.findAssistantIndex(guid) != -1
+	}
+	return false
+}
+
+// RaidSubGroupOf returns the sub-group index of guid, or -1 if guid is not placed in the raid
+func (ts *TeamSystem) RaidSubGroupOf(teamID, guid uint64) int {
+	if raid, ok := ts.raids[teamID]; ok {
+		group, _ := raid.findMember(guid)
+		return group
+	}
+	return -1
+}
+
+// RaidSubGroupMembers returns a copy of the members of one sub-group
+func (ts *TeamSystem) RaidSubGroupMembers(teamID uint64, group int) GuidVector {
+	raid, ok := ts.raids[teamID]
+	if !ok || group < 0 || group >= kRaidSubGroupCount {
+		return nil
+	}
+	members := make(GuidVector, len(raid.SubGroups[group]))
+	copy(members, raid.SubGroups[group])
+	return members
+}
+
+// RaidLayout returns a copy of every sub-group of the raid, indexed by sub-group
+func (ts *TeamSystem) RaidLayout(teamID uint64) []GuidVector {
+	if _, ok := ts.raids[teamID]; !ok {
+		return nil
+	}
+	layout := make([]GuidVector, kRaidSubGroupCount)
+	for group := range layout {
+		layout[group] = ts.RaidSubGroupMembers(teamID, group)
+	}
+	return layout
+}
+
+// SetRaidAssistant grants or revokes the assistant rank of guid, only the leader may change it
+func (ts *TeamSystem) SetRaidAssistant(teamID, currentLeaderID, guid uint64, assistant bool) uint32 {
+	call := ts.beginOp(OpSetRaidAssistant, teamID, currentLeaderID, guid)
+	call.args = &OpArgs{Assistant: assistant}
+	return ts.endOp(call, ts.setRaidAssistant(teamID, currentLeaderID, guid, assistant))
+}
+
+func (ts *TeamSystem) setRaidAssistant(teamID, currentLeaderID, guid uint64, assistant bool) uint32 {
+	raid, ok := ts.raids[teamID]
+	if !ok {
+		return ts.raidTeamError(teamID)
+	}
+	if ts.teams[teamID].LeaderID != currentLeaderID {
+		return kTeamAppointNotLeader
+	}
+	if currentLeaderID == guid {
+		return kTeamAppointSelf
+	}
+	if !ts.HasMember(teamID, guid) {
+		return kTeamMemberNotInTeam
+	}
+
+	idx := raid.findAssistantIndex(guid)
+	if assistant && idx == -1 {
+		raid.Assistants = append(raid.Assistants, guid)
+	} else if !assistant && idx != -1 {
+		raid.Assistants = append(raid.Assistants[:idx], raid.Assistants[idx+1:]...)
+	}
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// MoveRaidMember moves guid into the given sub-group, which must have a free slot
+func (ts *TeamSystem) MoveRaidMember(teamID, operatorID, guid uint64, group int) uint32 {
+	call := ts.beginOp(OpMoveRaidMember, teamID, operatorID, guid)
+	call.args = &OpArgs{Group: group}
+	return ts.endOp(call, ts.moveRaidMember(teamID, operatorID, guid, group))
+}
+
+func (ts *TeamSystem) moveRaidMember(teamID, operatorID, guid uint64, group int) uint32 {
+	raid, ok := ts.raids[teamID]
+	if !ok {
+		return ts.raidTeamError(teamID)
+	}
+	if !ts.isRaidOfficer(raid, operatorID) {
+		return kTeamRaidNotAssistant
+	}
+	if group < 0 || group >= kRaidSubGroupCount {
+		return kTeamRaidSubGroupIndex
+	}
+	from, idx := raid.findMember(guid)
+	if from == -1 {
+		return kTeamMemberNotInTeam
+	}
+	if from == group {
+		return kOK
+	}
+	if len(raid.SubGroups[group]) >= kRaidSubGroupSize {
+		return kTeamRaidSubGroupFull
+	}
+
+	raid.removeAt(from, idx)
+	raid.SubGroups[group] = append(raid.SubGroups[group], guid)
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+// SwapRaidMembers exchanges the sub-groups of two raid members
+func (ts *TeamSystem) SwapRaidMembers(teamID, operatorID, first, second uint64) uint32 {
+	call := ts.beginOp(OpSwapRaidMembers, teamID, operatorID, first)
+	call.args = &OpArgs{SecondID: second}
+	return ts.endOp(call, ts.swapRaidMembers(teamID, operatorID, first, second))
+}
+
+func (ts *TeamSystem) swapRaidMembers(teamID, operatorID, first, second uint64) uint32 {
+	raid, ok := ts.raids[teamID]
+	if !ok {
+		return ts.raidTeamError(teamID)
+	}
+	if !ts.isRaidOfficer(raid, operatorID) {
+		return kTeamRaidNotAssistant
+	}
+	firstGroup, firstIdx := raid.findMember(first)
+	secondGroup, secondIdx := raid.findMember(second)
+	if firstGroup == -1 || secondGroup == -1 {
+		return kTeamMemberNotInTeam
+	}
+
+	raid.SubGroups[firstGroup][firstIdx] = second
+	raid.SubGroups[secondGroup][secondIdx] = first
+	ts.teamChanged(teamID)
+	return kOK
+}
+
+func (ts *TeamSystem) raidTeamError(teamID uint64) uint32 {
+	if _, ok := ts.teams[teamID]; ok {
+		return kTeamNotRaid
+	}
+	return kTeamHasNotTeamId
+}
+
+func (ts *TeamSystem) isRaidOfficer(raid *Raid, guid uint64) bool {
+	if team, ok := ts.teams[raid.TeamID]; ok && team.LeaderID == guid {
+		return true
+	}
+	return raid.findAssistantIndex(guid) != -1
+}
+
+func (ts *TeamSystem) raidMemberJoined(team *Team, guid uint64) {
+	raid, ok := ts.raids[team.ID]
+	if !ok {
+		return
+	}
+	if group, _ := raid.findMember(guid); group != -1 {
+		return
+	}
+	for group := range raid.SubGroups {
+		if len(raid.SubGroups[group]) < kRaidSubGroupSize {
+			raid.SubGroups[group] = append(raid.SubGroups[group], guid)
+			return
+		}
+	}
+}
+
+func (ts *TeamSystem) raidMemberRemoved(team *Team, guid uint64) {
+	raid, ok := ts.raids[team.ID]
+	if !ok {
+		return
+	}
+	if group, idx := raid.findMember(guid); group != -1 {
+		raid.removeAt(group, idx)
+	}
+	if idx := raid.findAssistantIndex(guid); idx != -1 {
+		raid.Assistants = append(raid.Assistants[:idx], raid.Assistants[idx+1:]...)
+	}
+}
+
+func (raid *Raid) findMember(guid uint64) (int, int) {
+	for group, members := range raid.SubGroups {
+		for idx, member := range members {
+			if member == guid {
+				return group, idx
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (raid *Raid) findAssistantIndex(guid uint64) int {
+	for idx, assistant := range raid.Assistants {
+		if assistant == guid {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (raid *Raid) removeAt(group, idx int) {
+	members := raid.SubGroups[group]
+	raid.SubGroups[group] = append(members[:idx], members[idx+1:]...)
+}
@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestCreateRaidSubGroups(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(1)
+
+	if got := ts.CreateRaid(NewCreateTeamParam(leaderID, []uint64{1, 2, 3, 4, 5, 6, 7})); got != kOK {
+		t.Errorf("CreateRaid() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+	if !ts.IsRaid(teamID) {
+		t.Errorf("Expected team %v to be a raid", teamID)
+	}
+	if got := len(ts.RaidSubGroupMembers(teamID, 0)); got != kRaidSubGroupSize {
+		t.Errorf("RaidSubGroupMembers(0) size = %v, want %v", got, kRaidSubGroupSize)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 7); got != 1 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, 1)
+	}
+
+	for guid := uint64(8); guid <= kRaidMaxSize; guid++ {
+		if got := ts.JoinTeam(teamID, guid); got != kOK {
+			t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+		}
+	}
+	if got := ts.JoinTeam(teamID, kRaidMaxSize+1); got != kTeamMembersFull {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamMembersFull)
+	}
+	for group, members := range ts.RaidLayout(teamID) {
+		if len(members) != kRaidSubGroupSize {
+			t.Errorf("sub-group %v size = %v, want %v", group, len(members), kRaidSubGroupSize)
+		}
+	}
+
+	if got := ts.LeaveTeam(3); got != kOK {
+		t.Errorf("LeaveTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 3); got != -1 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, -1)
+	}
+	if got := ts.JoinTeam(teamID, 100); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 100); got != 0 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, 0)
+	}
+
+	if got := ts.Disbanded(teamID, leaderID); got != kOK {
+		t.Errorf("Disbanded() = %v, want %v", got, kOK)
+	}
+	if ts.IsRaid(teamID) {
+		t.Errorf("Expected raid %v to be removed", teamID)
+	}
+}
+
+func TestRaidMoveAndSwap(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(1)
+
+	if got := ts.CreateRaid(NewCreateTeamParam(leaderID, []uint64{1, 2, 3, 4, 5, 6})); got != kOK {
+		t.Errorf("CreateRaid() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	if got := ts.MoveRaidMember(teamID, 2, 3, 2); got != kTeamRaidNotAssistant {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kTeamRaidNotAssistant)
+	}
+	if got := ts.SetRaidAssistant(teamID, 2, 2, true); got != kTeamAppointNotLeader {
+		t.Errorf("SetRaidAssistant() = %v, want %v", got, kTeamAppointNotLeader)
+	}
+	if got := ts.SetRaidAssistant(teamID, leaderID, 2, true); got != kOK {
+		t.Errorf("SetRaidAssistant() = %v, want %v", got, kOK)
+	}
+	if got := ts.MoveRaidMember(teamID, 2, 3, 2); got != kOK {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kOK)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 3); got != 2 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, 2)
+	}
+	if got := ts.MoveRaidMember(teamID, leaderID, 3, kRaidSubGroupCount); got != kTeamRaidSubGroupIndex {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kTeamRaidSubGroupIndex)
+	}
+	if got := ts.MoveRaidMember(teamID, leaderID, 6, 0); got != kOK {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kOK)
+	}
+	if got := ts.MoveRaidMember(teamID, leaderID, 3, 0); got != kTeamRaidSubGroupFull {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kTeamRaidSubGroupFull)
+	}
+
+	if got := ts.SwapRaidMembers(teamID, leaderID, 1, 3); got != kOK {
+		t.Errorf("SwapRaidMembers() = %v, want %v", got, kOK)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 1); got != 2 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, 2)
+	}
+	if got := ts.RaidSubGroupOf(teamID, 3); got != 0 {
+		t.Errorf("RaidSubGroupOf() = %v, want %v", got, 0)
+	}
+	if got := ts.SwapRaidMembers(teamID, leaderID, 1, 99); got != kTeamMemberNotInTeam {
+		t.Errorf("SwapRaidMembers() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+
+	if got := ts.KickMember(teamID, leaderID, 2); got != kOK {
+		t.Errorf("KickMember() = %v, want %v", got, kOK)
+	}
+	if ts.IsRaidAssistant(teamID, 2) {
+		t.Errorf("Expected kicked member to lose assistant")
+	}
+
+	ts.CreateTeam(NewCreateTeamParam(50, []uint64{50}))
+	if got := ts.MoveRaidMember(ts.LastTeamID(), 50, 50, 1); got != kTeamNotRaid {
+		t.Errorf("MoveRaidMember() = %v, want %v", got, kTeamNotRaid)
+	}
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket refilled at Rate tokens per second up to Burst tokens.
+// A zero Burst disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits are the limits of one operation on one team type
+type RateLimits struct {
+	Player RateLimit // Per acting player, shared by every team of the type
+	Team   RateLimit // Per team
+}
+
+// Scopes of the team_rate_limited_total metric
+const (
+	rateLimitPlayer = "player"
+	rateLimitTeam   = "team"
+)
+
+type rateLimitKey struct {
+	op       Op
+	typeSize uint64
+}
+
+type bucketKey struct {
+	rateLimitKey
+	scope string
+	id    uint64 // Player ID or team ID depending on scope
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter keeps the configured limits and the buckets of the players and teams that used them
+type rateLimiter struct {
+	limits  map[rateLimitKey]RateLimits
+	buckets map[bucketKey]*tokenBucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits:  make(map[rateLimitKey]RateLimits),
+		buckets: make(map[bucketKey]*tokenBucket),
+	}
+}
+
+// rateLimited reports whether op can be limited, the limit applies to the player named below
+//
+//	ApplyToTeam   the applicant
+//	InviteToTeam  the inviter
+//	JoinTeam      the joining player
+//	KickMember    the leader
+func (op Op) rateLimited() bool {
+	switch op {
+	case OpApplyToTeam, OpInviteToTeam, OpJoinTeam, OpKickMember:
+		return true
+	}
+	return false
+}
+
+// SetRateLimits limits op on teams of teamTypeSize, a teamTypeSize of 0 sets the default of
+// the types without their own limits. It returns false for an operation that cannot be limited.
+func (ts *TeamSystem) SetRateLimits(op Op, teamTypeSize uint64, limits RateLimits) bool {
+	if !op.rateLimited() {
+		return false
+	}
+	key := rateLimitKey{op: op, typeSize: teamTypeSize}
+	if limits.Player.Burst <= 0 && limits.Team.Burst <= 0 {
+		delete(ts.limiter.limits, key)
+	} else {
+		ts.limiter.limits[key] = limits
+	}
+	return true
+}
+
+// PurgeRateLimits drops the buckets that have refilled completely and returns how many were removed
+func (ts *TeamSystem) PurgeRateLimits() int {
+	now := ts.now()
+	removed := 0
+	for key, bucket := range ts.limiter.buckets {
+		limits, ok := ts.limiter.limitsOf(key.op, key.typeSize)
+		limit := limits.Team
+		if key.scope == rateLimitPlayer {
+			limit = limits.Player
+		}
+		if !ok || limit.refill(bucket, now) >= float64(limit.Burst) {
+			delete(ts.limiter.buckets, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// checkRateLimit takes a token from the buckets of playerID and teamID, or returns kTeamRateLimited
+// if either is empty. Calls on missing teams are left to the operation to reject.
+func (ts *TeamSystem) checkRateLimit(op Op, teamID, playerID uint64) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kOK
+	}
+	if scope := ts.limiter.take(op, team.TeamTypeSize, playerID, teamID, ts.now()); scope != "" {
+		ts.metrics.rateLimited(op, scope)
+		return kTeamRateLimited
+	}
+	return kOK
+}
+
+func (l *rateLimiter) limitsOf(op Op, typeSize uint64) (RateLimits, bool) {
+	if limits, ok := l.limits[rateLimitKey{op: op, typeSize: typeSize}]; ok {
+		return limits, true
+	}
+	limits, ok := l.limits[rateLimitKey{op: op}]
+	return limits, ok
+}
+
+// take returns the scope of the empty bucket, or "" after taking a token from each limited bucket
+func (l *rateLimiter) take(op Op, typeSize, playerID, teamID uint64, now time.Time) string {
+	limits, ok := l.limitsOf(op, typeSize)
+	if !ok {
+		return ""
+	}
+	key := rateLimitKey{op: op, typeSize: typeSize}
+	player := l.bucket(bucketKey{rateLimitKey: key, scope: rateLimitPlayer, id: playerID}, limits.Player, now)
+	team := l.bucket(bucketKey{rateLimitKey: key, scope: rateLimitTeam, id: teamID}, limits.Team, now)
+	if player != nil && player.tokens < 1 {
+		return rateLimitPlayer
+	}
+	if team != nil && team.tokens < 1 {
+		return rateLimitTeam
+	}
+	if player != nil {
+		player.tokens--
+	}
+	if team != nil {
+		team.tokens--
+	}
+	return ""
+}
+
+// bucket returns the refilled bucket of key, or nil if limit is disabled
+func (l *rateLimiter) bucket(key bucketKey, limit RateLimit, now time.Time) *tokenBucket {
+	if limit.Burst <= 0 {
+		return nil
+	}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = bucket
+	}
+	limit.refill(bucket, now)
+	return bucket
+}
+
+func (limit RateLimit) refill(bucket *tokenBucket, now time.Time) float64 {
+	if elapsed := now.Sub(bucket.updated).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+		bucket.updated = now
+	}
+	return bucket.tokens
+}
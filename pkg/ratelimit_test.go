@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayerRateLimit(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+	ts.SetRateLimits(OpApplyToTeam, 0, RateLimits{Player: RateLimit{Rate: 0.5, Burst: 3}})
+
+	teamIDs := make([]uint64, 0, 5)
+	for leader := uint64(100); leader < 105; leader++ {
+		ts.CreateTeam(NewCreateTeamParam(leader, []uint64{leader}))
+		teamIDs = append(teamIDs, ts.LastTeamID())
+	}
+
+	for i, teamID := range teamIDs {
+		want := uint32(kOK)
+		if i >= 3 {
+			want = kTeamRateLimited
+		}
+		if got := ts.ApplyToTeam(teamID, 500); got != want {
+			t.Errorf("ApplyToTeam(%v) = %v, want %v", teamID, got, want)
+		}
+	}
+	// Other players keep their own bucket
+	if got := ts.ApplyToTeam(teamIDs[3], 501); got != kOK {
+		t.Errorf("ApplyToTeam() by another player = %v, want %v", got, kOK)
+	}
+
+	now = now.Add(2 * time.Second)
+	if got := ts.ApplyToTeam(teamIDs[3], 500); got != kOK {
+		t.Errorf("ApplyToTeam() after refill = %v, want %v", got, kOK)
+	}
+	if got := ts.ApplyToTeam(teamIDs[4], 500); got != kTeamRateLimited {
+		t.Errorf("ApplyToTeam() after one refilled token = %v, want %v", got, kTeamRateLimited)
+	}
+
+	var buf strings.Builder
+	ts.WriteMetrics(&buf)
+	for _, line := range []string{
+		`team_rate_limited_total{op="ApplyToTeam",scope="player"} 3`,
+		`team_operation_failures_total{op="ApplyToTeam",code="5035"} 3`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics output missing %q\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestTeamRateLimitByType(t *testing.T) {
+	ts := NewTeamSystem()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.SetClock(func() time.Time { return now })
+	ts.SetRateLimits(OpJoinTeam, kTenMemberMaxSize, RateLimits{Team: RateLimit{Rate: 1, Burst: 2}})
+	if ts.SetRateLimits(OpLeaveTeam, 0, RateLimits{Team: RateLimit{Rate: 1, Burst: 1}}) {
+		t.Errorf("SetRateLimits(LeaveTeam) = true, want false")
+	}
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}, kTenMemberMaxSize))
+	big := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}))
+	small := ts.LastTeamID()
+
+	for guid := uint64(300); guid < 303; guid++ {
+		ts.JoinTeam(big, guid)
+	}
+	if got := ts.MemberSize(big); got != 3 {
+		t.Errorf("MemberSize() of the limited type = %v, want %v", got, 3)
+	}
+	for guid := uint64(400); guid < 403; guid++ {
+		if got := ts.JoinTeam(small, guid); got != kOK {
+			t.Errorf("JoinTeam() on an unlimited type = %v, want %v", got, kOK)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if got := ts.PurgeRateLimits(); got != 1 {
+		t.Errorf("PurgeRateLimits() = %v, want %v", got, 1)
+	}
+	if got := ts.JoinTeam(big, 303); got != kOK {
+		t.Errorf("JoinTeam() after purge = %v, want %v", got, kOK)
+	}
+}
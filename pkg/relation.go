@@ -0,0 +1,179 @@
+package pkg
+
+import "sort"
+
+// RelationProvider answers social relationship queries between players
+type RelationProvider interface {
+	// IsBlocked reports whether guid has blocked other
+	IsBlocked(guid, other uint64) bool
+	IsFriend(guid, other uint64) bool
+}
+
+// LocalRelationProvider is an in-memory RelationProvider
+type LocalRelationProvider struct {
+	blocked map[uint64]map[uint64]struct{}
+	friends map[uint64]map[uint64]struct{}
+}
+
+func NewLocalRelationProvider() *LocalRelationProvider {
+	return &LocalRelationProvider{
+		blocked: make(map[uint64]map[uint64]struct{}),
+		friends: make(map[uint64]map[uint64]struct{}),
+	}
+}
+
+func (p *LocalRelationProvider) Block(guid, other uint64) {
+	addRelation(p.blocked, guid, other)
+}
+
+func (p *LocalRelationProvider) Unblock(guid, other uint64) {
+	delete(p.blocked[guid], other)
+}
+
+// AddFriend makes both players friends of each other
+func (p *LocalRelationProvider) AddFriend(guid, other uint64) {
+	addRelation(p.friends, guid, other)
+	addRelation(p.friends, other, guid)
+}
+
+func (p *LocalRelationProvider) RemoveFriend(guid, other uint64) {
+	delete(p.friends[guid], other)
+	delete(p.friends[other], guid)
+}
+
+func (p *LocalRelationProvider) IsBlocked(guid, other uint64) bool {
+	_, ok := p.blocked[guid][other]
+	return ok
+}
+
+func (p *LocalRelationProvider) IsFriend(guid, other uint64) bool {
+	_, ok := p.friends[guid][other]
+	return ok
+}
+
+func addRelation(relations map[uint64]map[uint64]struct{}, guid, other uint64) {
+	set, ok := relations[guid]
+	if !ok {
+		set = make(map[uint64]struct{})
+		relations[guid] = set
+	}
+	set[other] = struct{}{}
+}
+
+// SetRelationProvider sets the provider consulted for blocks and friendships, nil disables the checks
+func (ts *TeamSystem) SetRelationProvider(provider RelationProvider) {
+	ts.relations = provider
+}
+
+// IsBlockedBetween reports whether either player has blocked the other
+func (ts *TeamSystem) IsBlockedBetween(guid, other uint64) bool {
+	if ts.relations == nil {
+		return false
+	}
+	return ts.relations.IsBlocked(guid, other) || ts.relations.IsBlocked(other, guid)
+}
+
+// IsBlockedByTeam reports whether guid and any member of the team have blocked each other
+func (ts *TeamSystem) IsBlockedByTeam(teamID, guid uint64) bool {
+	if team, ok := ts.teams[teamID]; ok && ts.relations != nil {
+		for _, member := range team.MemberList {
+			if ts.IsBlockedBetween(member.Guid, guid) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasFriendInTeam reports whether guid is a friend of any member of the team
+func (ts *TeamSystem) HasFriendInTeam(teamID, guid uint64) bool {
+	if team, ok := ts.teams[teamID]; ok && ts.relations != nil {
+		for _, member := range team.MemberList {
+			if ts.relations.IsFriend(member.Guid, guid) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InviteToTeam adds inviteeID to the team on behalf of inviterID, who must be a member
+func (ts *TeamSystem) InviteToTeam(teamID, inviterID, inviteeID uint64) uint32 {
+	call := ts.beginOp(OpInviteToTeam, teamID, inviterID, inviteeID)
+	if err := ts.checkRateLimit(OpInviteToTeam, teamID, inviterID); err != kOK {
+		return ts.endOp(call, err)
+	}
+	return ts.endOp(call, ts.inviteToTeam(teamID, inviterID, inviteeID))
+}
+
+func (ts *TeamSystem) inviteToTeam(teamID, inviterID, inviteeID uint64) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if !ts.HasMember(teamID, inviterID) {
+		return kTeamMemberNotInTeam
+	}
+	if ts.HasTeam(inviteeID) {
+		return kTeamMemberInTeam
+	}
+	if ts.IsBlockedByTeam(teamID, inviteeID) {
+		return kTeamPlayerBlocked
+	}
+	if err := ts.checkGuild(team, inviteeID); err != kOK {
+		return err
+	}
+	return ts.joinTeam(teamID, inviteeID, JoinInvited)
+}
+
+// VisibleApplicants returns the applicants of the team that viewerID has no block relation with
+func (ts *TeamSystem) VisibleApplicants(teamID, viewerID uint64) GuidVector {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return nil
+	}
+	applicants := make(GuidVector, 0, len(team.Applicants))
+	for _, applicant := range team.Applicants {
+		if !ts.IsBlockedBetween(viewerID, applicant) {
+			applicants = append(applicants, applicant)
+		}
+	}
+	return applicants
+}
+
+// ListJoinableTeams is the team finder, returning the IDs of the teams guid could join in ascending order
+func (ts *TeamSystem) ListJoinableTeams(guid uint64) []uint64 {
+	teamIDs := make([]uint64, 0)
+	for teamID, team := range ts.teams {
+		if ts.IsTeamFull(teamID) || ts.IsBlockedByTeam(teamID, guid) {
+			continue
+		}
+		if ts.checkGuild(team, guid) != kOK {
+			continue
+		}
+		if ts.CheckLockout(teamID, guid) != kOK {
+			continue
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	return teamIDs
+}
+
+// checkBlockedMemberList rejects a member list that has block relations with the team or within itself
+func (ts *TeamSystem) checkBlockedMemberList(teamID uint64, memberList GuidVector) uint32 {
+	if ts.relations == nil {
+		return kOK
+	}
+	for i, member := range memberList {
+		if ts.IsBlockedByTeam(teamID, member) {
+			return kTeamPlayerBlocked
+		}
+		for _, other := range memberList[:i] {
+			if ts.IsBlockedBetween(member, other) {
+				return kTeamPlayerBlocked
+			}
+		}
+	}
+	return kOK
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlockedApplyAndJoin(t *testing.T) {
+	ts := NewTeamSystem()
+	relations := NewLocalRelationProvider()
+	ts.SetRelationProvider(relations)
+
+	if got := ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	if got := ts.ApplyToTeam(teamID, 200); got != kOK {
+		t.Errorf("ApplyToTeam() = %v, want %v", got, kOK)
+	}
+	relations.Block(101, 200)
+	relations.Block(300, 100)
+
+	if got := ts.VisibleApplicants(teamID, 101); len(got) != 0 {
+		t.Errorf("VisibleApplicants() = %v, want none", got)
+	}
+	if got := ts.VisibleApplicants(teamID, 100); !reflect.DeepEqual(got, GuidVector{200}) {
+		t.Errorf("VisibleApplicants() = %v, want %v", got, GuidVector{200})
+	}
+	if got := ts.ApplyToTeam(teamID, 300); got != kTeamPlayerBlocked {
+		t.Errorf("ApplyToTeam() = %v, want %v", got, kTeamPlayerBlocked)
+	}
+	if got := ts.JoinTeam(teamID, 200); got != kTeamPlayerBlocked {
+		t.Errorf("JoinTeam() = %v, want %v", got, kTeamPlayerBlocked)
+	}
+	if got := ts.JoinTeamByMemberList(GuidVector{400, 300}, teamID); got != kTeamPlayerBlocked {
+		t.Errorf("JoinTeamByMemberList() = %v, want %v", got, kTeamPlayerBlocked)
+	}
+	if ts.HasTeam(400) {
+		t.Errorf("Expected 400 not to join when another member is blocked")
+	}
+
+	relations.Block(500, 400)
+	if got := ts.JoinTeamByMemberList(GuidVector{400, 500}, teamID); got != kTeamPlayerBlocked {
+		t.Errorf("JoinTeamByMemberList() = %v, want %v", got, kTeamPlayerBlocked)
+	}
+
+	relations.Unblock(101, 200)
+	if got := ts.JoinTeam(teamID, 200); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+}
+
+func TestInviteAndTeamFinder(t *testing.T) {
+	ts := NewTeamSystem()
+	relations := NewLocalRelationProvider()
+	ts.SetRelationProvider(relations)
+
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	firstTeamID := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(200, []uint64{200}))
+	secondTeamID := ts.LastTeamID()
+	ts.CreateTeam(NewCreateTeamParam(300, []uint64{300, 301, 302, 303, 304}))
+
+	relations.Block(200, 900)
+	if got := ts.ListJoinableTeams(900); !reflect.DeepEqual(got, []uint64{firstTeamID}) {
+		t.Errorf("ListJoinableTeams() = %v, want %v", got, []uint64{firstTeamID})
+	}
+	if got := ts.ListJoinableTeams(901); !reflect.DeepEqual(got, []uint64{firstTeamID, secondTeamID}) {
+		t.Errorf("ListJoinableTeams() = %v, want %v", got, []uint64{firstTeamID, secondTeamID})
+	}
+
+	if got := ts.InviteToTeam(secondTeamID, 900, 901); got != kTeamMemberNotInTeam {
+		t.Errorf("InviteToTeam() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	if got := ts.InviteToTeam(secondTeamID, 200, 900); got != kTeamPlayerBlocked {
+		t.Errorf("InviteToTeam() = %v, want %v", got, kTeamPlayerBlocked)
+	}
+	if got := ts.InviteToTeam(secondTeamID, 200, 100); got != kTeamMemberInTeam {
+		t.Errorf("InviteToTeam() = %v, want %v", got, kTeamMemberInTeam)
+	}
+	if got := ts.InviteToTeam(secondTeamID, 200, 901); got != kOK {
+		t.Errorf("InviteToTeam() = %v, want %v", got, kOK)
+	}
+	if !ts.HasMember(secondTeamID, 901) {
+		t.Errorf("Expected invited player to be in team")
+	}
+
+	relations.AddFriend(901, 555)
+	if !ts.HasFriendInTeam(secondTeamID, 555) {
+		t.Errorf("Expected 555 to have a friend in team")
+	}
+}
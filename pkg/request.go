@@ -0,0 +1,81 @@
+package pkg
+
+import "context"
+
+// kRequestCacheSize is the default number of request IDs remembered for deduplication
+const kRequestCacheSize = 4096
+
+type requestIDKey struct{}
+
+// WithRequestID attaches a client request ID to ctx. The context-aware operations such as
+// ApplyToTeamCtx run once per request ID and return the first result to retries.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom returns the request ID attached to ctx, if any
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// WithRequestCache remembers the results of the last size request IDs, 0 disables deduplication
+func WithRequestCache(size int) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		ts.requests = newRequestCache(size)
+	}
+}
+
+// requestKey scopes a request ID to one operation so IDs reused across operations do not collide
+type requestKey struct {
+	op        Op
+	requestID string
+}
+
+// requestResult is the outcome of a request, teamID is the team created by CreateTeam and CreateRaid
+// and value what the call returned besides the result, such as the awards of DistributeLoot
+type requestResult struct {
+	result uint32
+	teamID uint64
+	value  any
+}
+
+// requestCache keeps the results of recent requests, evicting the oldest once full
+type requestCache struct {
+	results map[requestKey]requestResult
+	order   *ring[requestKey]
+}
+
+func newRequestCache(size int) *requestCache {
+	if size < 0 {
+		size = 0
+	}
+	return &requestCache{results: make(map[requestKey]requestResult, size), order: newRing[requestKey](size)}
+}
+
+// CreatedTeamID returns the team created by the CreateTeamCtx or CreateRaidCtx call, op, carrying
+// requestID. A retried create returns the first result, the team it created is found here
+// as long as the request is remembered.
+func (ts *TeamSystem) CreatedTeamID(op Op, requestID string) (uint64, bool) {
+	result, ok := ts.requests.lookup(op, requestID)
+	if !ok || result.teamID == kInvalidGuid {
+		return kInvalidGuid, false
+	}
+	return result.teamID, true
+}
+
+func (c *requestCache) lookup(op Op, requestID string) (requestResult, bool) {
+	result, ok := c.results[requestKey{op: op, requestID: requestID}]
+	return result, ok
+}
+
+func (c *requestCache) store(op Op, requestID string, result requestResult) {
+	if cap(c.order.items) == 0 {
+		return
+	}
+	key := requestKey{op: op, requestID: requestID}
+	if evicted, ok := c.order.push(key); ok {
+		delete(c.results, evicted)
+	}
+	c.results[key] = result
+}
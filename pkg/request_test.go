@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRequestIDRetries(t *testing.T) {
+	ts := NewTeamSystem()
+	ctx := WithRequestID(context.Background(), "create-1")
+
+	if got := ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("CreateTeamCtx() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+	// Another caller creates a team before the retry arrives
+	ts.CreateTeam(NewCreateTeamParam(900, []uint64{900}))
+	if got := ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("retried CreateTeamCtx() = %v, want %v", got, kOK)
+	}
+	if got := ts.TeamSize(); got != 2 {
+		t.Errorf("TeamSize() after retry = %v, want %v", got, 2)
+	}
+	if got, ok := ts.CreatedTeamID(OpCreateTeam, "create-1"); !ok || got != teamID {
+		t.Errorf("CreatedTeamID() = %v, %v, want %v", got, ok, teamID)
+	}
+	if _, ok := ts.CreatedTeamID(OpCreateRaid, "create-1"); ok {
+		t.Errorf("CreatedTeamID() of another op = true, want false")
+	}
+
+	apply := WithRequestID(context.Background(), "apply-1")
+	for i := 0; i < 3; i++ {
+		if got := ts.ApplyToTeamCtx(apply, teamID, 200); got != kOK {
+			t.Errorf("ApplyToTeamCtx() attempt %v = %v, want %v", i, got, kOK)
+		}
+	}
+	// A new request is not a retry
+	if got := ts.ApplyToTeamCtx(WithRequestID(context.Background(), "apply-2"), teamID, 200); got != kTeamApplyJoin {
+		t.Errorf("ApplyToTeamCtx() with new request = %v, want %v", got, kTeamApplyJoin)
+	}
+	// The same ID on another operation is a different request
+	if got := ts.JoinTeamCtx(apply, teamID, 200); got != kOK {
+		t.Errorf("JoinTeamCtx() = %v, want %v", got, kOK)
+	}
+	// Calls without a request ID are never deduplicated
+	if got := ts.ApplyToTeamCtx(context.Background(), teamID, 300); got != kOK {
+		t.Errorf("ApplyToTeamCtx() = %v, want %v", got, kOK)
+	}
+	if got := ts.ApplyToTeamCtx(context.Background(), teamID, 300); got != kTeamApplyJoin {
+		t.Errorf("ApplyToTeamCtx() again = %v, want %v", got, kTeamApplyJoin)
+	}
+}
+
+func TestRequestIDRetriesReturnValues(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}))
+	teamID := ts.LastTeamID()
+
+	loot := WithRequestID(context.Background(), "loot-1")
+	awards, ret := ts.DistributeLootCtx(loot, teamID, lootItems(1, 2))
+	if ret != kOK || len(awards) != 2 {
+		t.Fatalf("DistributeLootCtx() = %v, %v, want 2 awards and %v", awards, ret, kOK)
+	}
+	// A retry must not roll again or advance the round robin
+	retried, ret := ts.DistributeLootCtx(loot, teamID, lootItems(1, 2))
+	if ret != kOK || !reflect.DeepEqual(retried, awards) {
+		t.Errorf("retried DistributeLootCtx() = %v, %v, want %v, %v", retried, ret, awards, kOK)
+	}
+	next, _ := ts.DistributeLootCtx(WithRequestID(context.Background(), "loot-2"), teamID, lootItems(3))
+	if len(next) != 1 || next[0].Winner != 102 {
+		t.Errorf("DistributeLootCtx() after retry = %v, want item for %v", next, 102)
+	}
+
+	schedule := WithRequestID(context.Background(), "schedule-1")
+	param := NewCreateTeamParam(200, []uint64{200}, 3)
+	scheduleID, ret := ts.ScheduleTeamCtx(schedule, param, time.Now().Add(time.Hour))
+	if ret != kOK {
+		t.Fatalf("ScheduleTeamCtx() = %v, want %v", ret, kOK)
+	}
+	if got, ret := ts.ScheduleTeamCtx(schedule, param, time.Now().Add(time.Hour)); ret != kOK || got != scheduleID {
+		t.Errorf("retried ScheduleTeamCtx() = %v, %v, want %v, %v", got, ret, scheduleID, kOK)
+	}
+	if got := ts.SchedulesOfPlayer(200); len(got) != 1 {
+		t.Errorf("SchedulesOfPlayer() after retry = %v, want one roster", got)
+	}
+}
+
+func TestRequestCacheEviction(t *testing.T) {
+	ts := NewTeamSystem(WithRequestCache(2))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+	teamID := ts.LastTeamID()
+
+	for _, requestID := range []string{"a", "b", "c"} {
+		ts.ApplyToTeamCtx(WithRequestID(context.Background(), requestID), teamID, 200)
+	}
+	// "a" was evicted, so its retry runs again and fails
+	if got := ts.ApplyToTeamCtx(WithRequestID(context.Background(), "a"), teamID, 200); got != kTeamApplyJoin {
+		t.Errorf("ApplyToTeamCtx(a) after eviction = %v, want %v", got, kTeamApplyJoin)
+	}
+	if got := ts.ApplyToTeamCtx(WithRequestID(context.Background(), "c"), teamID, 200); got != kTeamApplyJoin {
+		t.Errorf("ApplyToTeamCtx(c) = %v, want the recorded %v", got, kTeamApplyJoin)
+	}
+
+	ts = NewTeamSystem(WithRequestCache(0))
+	ctx := WithRequestID(context.Background(), "create")
+	ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100}))
+	if got := ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100})); got != kTeamMemberInTeam {
+		t.Errorf("CreateTeamCtx() with deduplication disabled = %v, want %v", got, kTeamMemberInTeam)
+	}
+}
+
+func TestRequestIDSpan(t *testing.T) {
+	recorder := NewSpanRecorder()
+	ts := NewTeamSystem(WithTracer(recorder))
+	ctx := WithRequestID(context.Background(), "create-1")
+	ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100}))
+	ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100}))
+
+	spans := recorder.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Spans() = %v spans, want %v", len(spans), 2)
+	}
+	if spans[0].Attributes["team.request_id"] != "create-1" || spans[0].Attributes["team.retried"] != nil {
+		t.Errorf("first span attributes = %v, want request ID without retry", spans[0].Attributes)
+	}
+	if spans[1].Attributes["team.retried"] != true || !spans[1].OK {
+		t.Errorf("retried span = %+v, want successful retry", spans[1])
+	}
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// RewardSplitRule is the rule used to share a reward between present members
+type RewardSplitRule uint8
+
+const (
+	RewardSplitEqual RewardSplitRule = iota
+	RewardSplitLevelWeighted
+	rewardSplitRuleCount
+)
+
+// RewardSplitParam represents parameters for splitting a reward
+type RewardSplitParam struct {
+	TeamID               uint64
+	Amount               uint64
+	Present              GuidVector // Members able to receive the reward, e.g. in range
+	Rule                 RewardSplitRule
+	Levels               map[uint64]uint32 // Member levels used by RewardSplitLevelWeighted
+	FullTeamBonusPercent uint64            // Extra reward in percent when the team is full
+}
+
+// RewardShare is the part of a reward given to one member
+type RewardShare struct {
+	Guid   uint64
+	Weight uint64
+	Amount uint64
+}
+
+// RewardSplitResult represents the outcome of SplitReward
+type RewardSplitResult struct {
+	TeamID uint64
+	Total  uint64 // Amount plus Bonus
+	Bonus  uint64
+	Shares []RewardShare // Ordered by Guid
+}
+
+// SplitReward shares the reward between the present members of the team.
+// kTeamRewardOverflow is returned when Amount plus the bonus does not fit in a uint64.
+// The remainder of the integer division goes one unit at a time to the members
+// with the largest fractional share, ties broken by the lowest guid.
+func (ts *TeamSystem) SplitReward(param RewardSplitParam) (RewardSplitResult, uint32) {
+	if _, ok := ts.teams[param.TeamID]; !ok {
+		return RewardSplitResult{}, kTeamHasNotTeamId
+	}
+	if param.Rule >= rewardSplitRuleCount {
+		return RewardSplitResult{}, kTeamRewardSplitRule
+	}
+
+	eligible := ts.presentMembers(param.TeamID, param.Present)
+	if len(eligible) == 0 {
+		return RewardSplitResult{}, kTeamMemberNotInTeam
+	}
+
+	result := RewardSplitResult{TeamID: param.TeamID, Total: param.Amount}
+	if ts.IsTeamFull(param.TeamID) {
+		hi, lo := bits.Mul64(param.Amount, param.FullTeamBonusPercent)
+		if hi >= 100 {
+			return RewardSplitResult{}, kTeamRewardOverflow
+		}
+		result.Bonus, _ = bits.Div64(hi, lo, 100)
+		var carry uint64
+		if result.Total, carry = bits.Add64(param.Amount, result.Bonus, 0); carry != 0 {
+			return RewardSplitResult{}, kTeamRewardOverflow
+		}
+	}
+
+	weightSum := uint64(0)
+	result.Shares = make([]RewardShare, len(eligible))
+	for i, guid := range eligible {
+		weight := uint64(1)
+		if param.Rule == RewardSplitLevelWeighted && param.Levels[guid] > 0 {
+			weight = uint64(param.Levels[guid])
+		}
+		result.Shares[i] = RewardShare{Guid: guid, Weight: weight}
+		weightSum += weight
+	}
+
+	remainders := make([]uint64, len(eligible))
+	distributed := uint64(0)
+	for i := range result.Shares {
+		hi, lo := bits.Mul64(result.Total, result.Shares[i].Weight)
+		result.Shares[i].Amount, remainders[i] = bits.Div64(hi, lo, weightSum)
+		distributed += result.Shares[i].Amount
+	}
+
+	order := make([]int, len(eligible))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := uint64(0); i < result.Total-distributed; i++ {
+		result.Shares[order[i]].Amount++
+	}
+	return result, kOK
+}
+
+// presentMembers returns the members of the team found in present, sorted and without duplicates
+func (ts *TeamSystem) presentMembers(teamID uint64, present GuidVector) GuidVector {
+	members := make(GuidVector, 0, len(present))
+	for _, guid := range present {
+		if ts.HasMember(teamID, guid) {
+			members = append(members, guid)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+
+	unique := members[:0]
+	for _, guid := range members {
+		if len(unique) == 0 || guid != unique[len(unique)-1] {
+			unique = append(unique, guid)
+		}
+	}
+	return unique
+}
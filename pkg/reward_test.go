@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSplitRewardEqual(t *testing.T) {
+	ts := NewTeamSystem()
+
+	if got := ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	result, ret := ts.SplitReward(RewardSplitParam{
+		TeamID:               teamID,
+		Amount:               100,
+		Present:              GuidVector{102, 100, 101, 100, 999},
+		Rule:                 RewardSplitEqual,
+		FullTeamBonusPercent: 50,
+	})
+	if ret != kOK {
+		t.Errorf("SplitReward() = %v, want %v", ret, kOK)
+	}
+	if result.Bonus != 0 || result.Total != 100 {
+		t.Errorf("SplitReward() total = %v bonus = %v, want 100 and 0", result.Total, result.Bonus)
+	}
+	want := []RewardShare{{100, 1, 34}, {101, 1, 33}, {102, 1, 33}}
+	if len(result.Shares) != len(want) {
+		t.Fatalf("SplitReward() shares = %v, want %v", result.Shares, want)
+	}
+	for i := range want {
+		if result.Shares[i] != want[i] {
+			t.Errorf("SplitReward() share[%v] = %v, want %v", i, result.Shares[i], want[i])
+		}
+	}
+
+	if _, got := ts.SplitReward(RewardSplitParam{TeamID: teamID, Amount: 1, Present: GuidVector{999}}); got != kTeamMemberNotInTeam {
+		t.Errorf("SplitReward() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	if _, got := ts.SplitReward(RewardSplitParam{TeamID: teamID, Rule: rewardSplitRuleCount}); got != kTeamRewardSplitRule {
+		t.Errorf("SplitReward() = %v, want %v", got, kTeamRewardSplitRule)
+	}
+	if _, got := ts.SplitReward(RewardSplitParam{TeamID: 999}); got != kTeamHasNotTeamId {
+		t.Errorf("SplitReward() = %v, want %v", got, kTeamHasNotTeamId)
+	}
+}
+
+func TestSplitRewardLevelWeightedFullTeam(t *testing.T) {
+	ts := NewTeamSystem()
+
+	if got := ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102, 103, 104})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+
+	result, ret := ts.SplitReward(RewardSplitParam{
+		TeamID:               ts.LastTeamID(),
+		Amount:               1000,
+		Present:              GuidVector{100, 101, 102},
+		Rule:                 RewardSplitLevelWeighted,
+		Levels:               map[uint64]uint32{100: 60, 101: 30, 102: 10},
+		FullTeamBonusPercent: 10,
+	})
+	if ret != kOK {
+		t.Errorf("SplitReward() = %v, want %v", ret, kOK)
+	}
+	if result.Bonus != 100 || result.Total != 1100 {
+		t.Errorf("SplitReward() total = %v bonus = %v, want 1100 and 100", result.Total, result.Bonus)
+	}
+
+	sum := uint64(0)
+	for _, share := range result.Shares {
+		sum += share.Amount
+	}
+	if sum != result.Total {
+		t.Errorf("SplitReward() shares sum = %v, want %v", sum, result.Total)
+	}
+	if got := result.Shares[0].Amount; got != 660 {
+		t.Errorf("SplitReward() share of level 60 = %v, want %v", got, 660)
+	}
+}
+
+func TestSplitRewardBonusOverflow(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}, 2))
+	param := RewardSplitParam{
+		TeamID:               ts.LastTeamID(),
+		Amount:               math.MaxUint64 / 2,
+		Present:              GuidVector{100, 101},
+		FullTeamBonusPercent: 50,
+	}
+	result, ret := ts.SplitReward(param)
+	if ret != kOK || result.Bonus != math.MaxUint64/4 || result.Total != math.MaxUint64/2+math.MaxUint64/4 {
+		t.Errorf("SplitReward() = %+v, %v, want bonus %v", result, ret, uint64(math.MaxUint64/4))
+	}
+
+	for _, percent := range []uint64{101, math.MaxUint64} {
+		param.FullTeamBonusPercent = percent
+		if _, ret := ts.SplitReward(param); ret != kTeamRewardOverflow {
+			t.Errorf("SplitReward() with %v%% bonus = %v, want %v", percent, ret, kTeamRewardOverflow)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package pkg
+
+// ring is a fixed capacity buffer that overwrites its oldest item once full
+type ring[T any] struct {
+	items []T
+	next  int // Index of the oldest item once the buffer is full
+}
+
+func newRing[T any](capacity int) *ring[T] {
+	return &ring[T]{items: make([]T, 0, capacity)}
+}
+
+// push returns the item it overwrote, if any
+func (r *ring[T]) push(item T) (evicted T, ok bool) {
+	if len(r.items) < cap(r.items) {
+		r.items = append(r.items, item)
+		return evicted, false
+	}
+	evicted = r.items[r.next]
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	return evicted, true
+}
+
+// all returns a copy of the items, oldest first
+func (r *ring[T]) all() []T {
+	items := make([]T, 0, len(r.items))
+	items = append(items, r.items[r.next:]...)
+	return append(items, r.items[:r.next]...)
+}
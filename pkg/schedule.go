@@ -0,0 +1,301 @@
+package pkg
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduledTeam is a roster formed ahead of an event. Its players keep their
+// playerLists slot free until ActivateSchedule turns the roster into a team.
+type ScheduledTeam struct {
+	ID           uint64     `json:"id"`
+	LeaderID     uint64     `json:"leader_id"`
+	StartAt      time.Time  `json:"start_at"`
+	TeamTypeSize uint64     `json:"team_type_size"`
+	GuildID      uint64     `json:"guild_id,omitempty"`
+	SignedUp     GuidVector `json:"signed_up"` // Roster in sign-up order, at most TeamTypeSize players
+	Standby      GuidVector `json:"standby"`   // Promoted in order when a roster slot frees up
+}
+
+func (s ScheduledTeam) clone() ScheduledTeam {
+	s.SignedUp = append(GuidVector{}, s.SignedUp...)
+	s.Standby = append(GuidVector{}, s.Standby...)
+	return s
+}
+
+func (s *ScheduledTeam) isSignedUp(guid uint64) bool {
+	return containsGuidVector(s.SignedUp, guid) || containsGuidVector(s.Standby, guid)
+}
+
+// ActivationReport describes how a roster was turned into a team
+type ActivationReport struct {
+	TeamID   uint64
+	LeaderID uint64     // Differs from the scheduled leader if the leader was dropped
+	Members  GuidVector // Members of the created team
+	Dropped  GuidVector // Signed-up players who had joined another team or left the guild
+	Promoted GuidVector // Standby players who took a roster slot
+}
+
+// ScheduleTeam creates a roster starting at startAt from param, the member list becomes the signed-up players
+func (ts *TeamSystem) ScheduleTeam(param CreateTeamParam, startAt time.Time) (uint64, uint32) {
+	call := ts.beginOp(OpScheduleTeam, kInvalidGuid, param.LeaderID, kInvalidGuid)
+	call.members, call.typeSize, call.guildID = param.MemberList, param.TeamTypeSize, param.GuildID
+	call.args = &OpArgs{StartAt: &startAt}
+	scheduleID, result := ts.scheduleTeam(param, startAt)
+	call.schedule = scheduleID
+	return scheduleID, ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) scheduleTeam(param CreateTeamParam, startAt time.Time) (uint64, uint32) {
+	if len(param.MemberList) > int(param.TeamTypeSize) {
+		return kInvalidGuid, kTeamCreateTeamMaxMemberSize
+	}
+	signedUp := make(GuidVector, 0, len(param.MemberList))
+	for _, guid := range param.MemberList {
+		if containsGuidVector(signedUp, guid) {
+			return kInvalidGuid, kTeamScheduleSignedUp
+		}
+		signedUp = append(signedUp, guid)
+	}
+	if param.GuildID != kInvalidGuid {
+		players := append(GuidVector{param.LeaderID}, signedUp...)
+		if len(ts.guildMembers(param.GuildID, players)) != len(players) {
+			return kInvalidGuid, kTeamNotInGuild
+		}
+	}
+
+	ts.lastScheduleID++
+	ts.schedules[ts.lastScheduleID] = &ScheduledTeam{
+		ID:           ts.lastScheduleID,
+		LeaderID:     param.LeaderID,
+		StartAt:      startAt,
+		TeamTypeSize: param.TeamTypeSize,
+		GuildID:      param.GuildID,
+		SignedUp:     signedUp,
+		Standby:      make(GuidVector, 0),
+	}
+	return ts.lastScheduleID, kOK
+}
+
+// ScheduledTeamOf returns a copy of the roster
+func (ts *TeamSystem) ScheduledTeamOf(scheduleID uint64) (ScheduledTeam, bool) {
+	if schedule, ok := ts.schedules[scheduleID]; ok {
+		return schedule.clone(), true
+	}
+	return ScheduledTeam{}, false
+}
+
+// SchedulesOfPlayer returns the rosters guid is signed up or on standby for, in ID order
+func (ts *TeamSystem) SchedulesOfPlayer(guid uint64) []uint64 {
+	scheduleIDs := make([]uint64, 0)
+	for scheduleID, schedule := range ts.schedules {
+		if schedule.isSignedUp(guid) {
+			scheduleIDs = append(scheduleIDs, scheduleID)
+		}
+	}
+	sort.Slice(scheduleIDs, func(i, j int) bool { return scheduleIDs[i] < scheduleIDs[j] })
+	return scheduleIDs
+}
+
+// SignUp adds guid to the roster, or to the standby list once the roster is full
+func (ts *TeamSystem) SignUp(scheduleID, guid uint64) uint32 {
+	call := ts.beginOp(OpSignUp, kInvalidGuid, guid, kInvalidGuid)
+	call.schedule = scheduleID
+	return ts.endOp(call, ts.signUp(scheduleID, guid))
+}
+
+func (ts *TeamSystem) signUp(scheduleID, guid uint64) uint32 {
+	schedule, ok := ts.schedules[scheduleID]
+	if !ok {
+		return kTeamScheduleNotFound
+	}
+	if schedule.isSignedUp(guid) {
+		return kTeamScheduleSignedUp
+	}
+	if schedule.GuildID != kInvalidGuid && !ts.IsGuildMember(schedule.GuildID, guid) {
+		return kTeamNotInGuild
+	}
+	if len(schedule.SignedUp) < int(schedule.TeamTypeSize) {
+		schedule.SignedUp = append(schedule.SignedUp, guid)
+	} else {
+		schedule.Standby = append(schedule.Standby, guid)
+	}
+	return kOK
+}
+
+// CancelSignUp removes guid from the roster or standby list, the first standby player takes a freed slot.
+// The leader cannot cancel, CancelSchedule drops the whole roster instead.
+func (ts *TeamSystem) CancelSignUp(scheduleID, guid uint64) uint32 {
+	call := ts.beginOp(OpCancelSignUp, kInvalidGuid, guid, kInvalidGuid)
+	call.schedule = scheduleID
+	return ts.endOp(call, ts.cancelSignUp(scheduleID, guid))
+}
+
+func (ts *TeamSystem) cancelSignUp(scheduleID, guid uint64) uint32 {
+	schedule, ok := ts.schedules[scheduleID]
+	if !ok {
+		return kTeamScheduleNotFound
+	}
+	if schedule.LeaderID == guid {
+		return kTeamScheduleLeader
+	}
+	if containsGuidVector(schedule.Standby, guid) {
+		schedule.Standby = removeGuidVector(schedule.Standby, guid)
+		return kOK
+	}
+	if !containsGuidVector(schedule.SignedUp, guid) {
+		return kTeamScheduleNotSignedUp
+	}
+	schedule.SignedUp = removeGuidVector(schedule.SignedUp, guid)
+	if len(schedule.Standby) > 0 {
+		schedule.SignedUp = append(schedule.SignedUp, schedule.Standby[0])
+		schedule.Standby = schedule.Standby[1:]
+	}
+	return kOK
+}
+
+// CancelSchedule drops the roster, only its leader may cancel it
+func (ts *TeamSystem) CancelSchedule(scheduleID, leaderID uint64) uint32 {
+	call := ts.beginOp(OpCancelSchedule, kInvalidGuid, leaderID, kInvalidGuid)
+	call.schedule = scheduleID
+	return ts.endOp(call, ts.cancelSchedule(scheduleID, leaderID))
+}
+
+func (ts *TeamSystem) cancelSchedule(scheduleID, leaderID uint64) uint32 {
+	schedule, ok := ts.schedules[scheduleID]
+	if !ok {
+		return kTeamScheduleNotFound
+	}
+	if schedule.LeaderID != leaderID {
+		return kTeamDismissNotLeader
+	}
+	delete(ts.schedules, scheduleID)
+	return kOK
+}
+
+// ActivateSchedule creates the team of the roster and drops the roster.
+// Players who have joined another team or left the guild since signing up are dropped and
+// standby players fill their slots. A dropped leader is replaced by the first remaining
+// member. If no member is left the roster is kept and kTeamScheduleEmpty is returned.
+func (ts *TeamSystem) ActivateSchedule(scheduleID uint64) (ActivationReport, uint32) {
+	call := ts.beginOp(OpActivateSchedule, kInvalidGuid, kInvalidGuid, kInvalidGuid)
+	call.schedule = scheduleID
+	report, result := ts.activateSchedule(scheduleID)
+	call.teamID = report.TeamID
+	return report, ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) activateSchedule(scheduleID uint64) (ActivationReport, uint32) {
+	schedule, ok := ts.schedules[scheduleID]
+	if !ok {
+		return ActivationReport{}, kTeamScheduleNotFound
+	}
+
+	report := ActivationReport{
+		LeaderID: schedule.LeaderID,
+		Members:  make(GuidVector, 0, len(schedule.SignedUp)),
+		Dropped:  make(GuidVector, 0),
+		Promoted: make(GuidVector, 0),
+	}
+	candidates := append(GuidVector{schedule.LeaderID}, schedule.SignedUp...)
+	candidates = append(candidates, schedule.Standby...)
+	var inGuild GuidVector
+	if schedule.GuildID != kInvalidGuid {
+		inGuild = ts.guildMembers(schedule.GuildID, candidates)
+	}
+	available := func(guid uint64) bool {
+		return !ts.HasTeam(guid) && (schedule.GuildID == kInvalidGuid || containsGuidVector(inGuild, guid))
+	}
+
+	leaderAvailable := available(schedule.LeaderID)
+	if !leaderAvailable {
+		report.Dropped = append(report.Dropped, schedule.LeaderID)
+	}
+	take := func(guid uint64) bool {
+		if guid == schedule.LeaderID {
+			return leaderAvailable
+		}
+		if !available(guid) {
+			report.Dropped = append(report.Dropped, guid)
+			return false
+		}
+		return true
+	}
+	for _, guid := range schedule.SignedUp {
+		if take(guid) {
+			report.Members = append(report.Members, guid)
+		}
+	}
+	for _, guid := range schedule.Standby {
+		if len(report.Members) >= int(schedule.TeamTypeSize) {
+			break
+		}
+		if take(guid) {
+			report.Members = append(report.Members, guid)
+			report.Promoted = append(report.Promoted, guid)
+		}
+	}
+	if len(report.Members) == 0 {
+		return ActivationReport{}, kTeamScheduleEmpty
+	}
+	if !leaderAvailable {
+		report.LeaderID = report.Members[0]
+	}
+
+	param := CreateTeamParam{
+		LeaderID:     report.LeaderID,
+		MemberList:   report.Members,
+		TeamTypeSize: schedule.TeamTypeSize,
+		GuildID:      schedule.GuildID,
+	}
+	if err := ts.createTeam(param); err != kOK {
+		return ActivationReport{}, err
+	}
+	report.TeamID = ts.lastTeamID
+	delete(ts.schedules, scheduleID)
+	return report, kOK
+}
+
+// ActivateDueSchedules calls ActivateSchedule on every roster whose start time has come, in start order.
+// Rosters that fail to activate are kept and left out of the result.
+func (ts *TeamSystem) ActivateDueSchedules() []ActivationReport {
+	now := ts.now()
+	due := make([]*ScheduledTeam, 0)
+	for _, schedule := range ts.schedules {
+		if !now.Before(schedule.StartAt) {
+			due = append(due, schedule)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].StartAt.Equal(due[j].StartAt) {
+			return due[i].StartAt.Before(due[j].StartAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	reports := make([]ActivationReport, 0, len(due))
+	for _, schedule := range due {
+		if report, err := ts.ActivateSchedule(schedule.ID); err == kOK {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func containsGuidVector(list GuidVector, guid uint64) bool {
+	for _, other := range list {
+		if other == guid {
+			return true
+		}
+	}
+	return false
+}
+
+func removeGuidVector(list GuidVector, guid uint64) GuidVector {
+	for idx, other := range list {
+		if other == guid {
+			return append(list[:idx], list[idx+1:]...)
+		}
+	}
+	return list
+}
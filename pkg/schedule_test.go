@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleSignUp(t *testing.T) {
+	ts := NewTeamSystem()
+	scheduleID, err := ts.ScheduleTeam(NewCreateTeamParam(100, []uint64{100, 101}, 3), time.Now().Add(time.Hour))
+	if err != kOK {
+		t.Fatalf("ScheduleTeam() = %v, want %v", err, kOK)
+	}
+	for _, tt := range []struct {
+		name string
+		run  func() uint32
+		want uint32
+	}{
+		{"SignUp", func() uint32 { return ts.SignUp(scheduleID, 102) }, kOK},
+		{"SignUp standby", func() uint32 { return ts.SignUp(scheduleID, 103) }, kOK},
+		{"SignUp twice", func() uint32 { return ts.SignUp(scheduleID, 103) }, kTeamScheduleSignedUp},
+		{"SignUp unknown schedule", func() uint32 { return ts.SignUp(scheduleID+1, 104) }, kTeamScheduleNotFound},
+		{"CancelSignUp leader", func() uint32 { return ts.CancelSignUp(scheduleID, 100) }, kTeamScheduleLeader},
+		{"CancelSignUp stranger", func() uint32 { return ts.CancelSignUp(scheduleID, 104) }, kTeamScheduleNotSignedUp},
+		{"CancelSignUp", func() uint32 { return ts.CancelSignUp(scheduleID, 101) }, kOK},
+	} {
+		if got := tt.run(); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	schedule, _ := ts.ScheduledTeamOf(scheduleID)
+	if want := (GuidVector{100, 102, 103}); !reflect.DeepEqual(schedule.SignedUp, want) {
+		t.Errorf("SignedUp = %v, want %v", schedule.SignedUp, want)
+	}
+	if len(schedule.Standby) != 0 {
+		t.Errorf("Standby = %v, want empty", schedule.Standby)
+	}
+	if ts.HasTeam(102) {
+		t.Errorf("HasTeam() of a signed-up player = true, want false")
+	}
+	if got := ts.CreateTeam(NewCreateTeamParam(102, []uint64{102})); got != kOK {
+		t.Errorf("CreateTeam() of a signed-up player = %v, want %v", got, kOK)
+	}
+	if got, want := ts.SchedulesOfPlayer(103), []uint64{scheduleID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SchedulesOfPlayer() = %v, want %v", got, want)
+	}
+
+	if got := ts.CancelSchedule(scheduleID, 102); got != kTeamDismissNotLeader {
+		t.Errorf("CancelSchedule() by a member = %v, want %v", got, kTeamDismissNotLeader)
+	}
+	if got := ts.CancelSchedule(scheduleID, 100); got != kOK {
+		t.Errorf("CancelSchedule() = %v, want %v", got, kOK)
+	}
+	if _, ok := ts.ScheduledTeamOf(scheduleID); ok {
+		t.Errorf("ScheduledTeamOf() after cancel = true, want false")
+	}
+}
+
+func TestActivateSchedule(t *testing.T) {
+	ts := NewTeamSystem()
+	scheduleID, _ := ts.ScheduleTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}, 3), time.Now())
+	ts.SignUp(scheduleID, 103)
+	ts.SignUp(scheduleID, 104)
+	ts.SignUp(scheduleID, 105)
+
+	// The leader and a member have joined other teams since signing up
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 103}))
+	ts.CreateTeam(NewCreateTeamParam(102, []uint64{102}))
+
+	report, err := ts.ActivateSchedule(scheduleID)
+	if err != kOK {
+		t.Fatalf("ActivateSchedule() = %v, want %v", err, kOK)
+	}
+	want := ActivationReport{
+		TeamID:   ts.LastTeamID(),
+		LeaderID: 101,
+		Members:  GuidVector{101, 104, 105},
+		Dropped:  GuidVector{100, 102, 103},
+		Promoted: GuidVector{104, 105},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("ActivateSchedule() = %+v, want %+v", report, want)
+	}
+	if got := ts.TeamMembers(report.TeamID).Guids(); !reflect.DeepEqual(got, want.Members) {
+		t.Errorf("TeamMembers() = %v, want %v", got, want.Members)
+	}
+	if got := ts.GetLeaderIDByTeamID(report.TeamID); got != 101 {
+		t.Errorf("GetLeaderIDByTeamID() = %v, want %v", got, 101)
+	}
+	if _, ok := ts.ScheduledTeamOf(scheduleID); ok {
+		t.Errorf("ScheduledTeamOf() after activation = true, want false")
+	}
+
+	emptyID, _ := ts.ScheduleTeam(NewCreateTeamParam(101, []uint64{101}, 2), time.Now())
+	if _, err := ts.ActivateSchedule(emptyID); err != kTeamScheduleEmpty {
+		t.Errorf("ActivateSchedule() with nobody available = %v, want %v", err, kTeamScheduleEmpty)
+	}
+	if _, ok := ts.ScheduledTeamOf(emptyID); !ok {
+		t.Errorf("ScheduledTeamOf() after failed activation = false, want true")
+	}
+}
+
+func TestActivateScheduleLeader(t *testing.T) {
+	ts := NewTeamSystem()
+	// The leader organizes the event without taking a roster slot
+	scheduleID, _ := ts.ScheduleTeam(NewCreateTeamParam(1, []uint64{2, 3}), time.Now())
+	report, err := ts.ActivateSchedule(scheduleID)
+	if err != kOK || report.LeaderID != 1 || len(report.Dropped) != 0 {
+		t.Errorf("ActivateSchedule() = %+v, %v, want leader %v and nobody dropped", report, err, 1)
+	}
+
+	// A leader outside the roster who joined another team is dropped and reported
+	scheduleID, _ = ts.ScheduleTeam(NewCreateTeamParam(10, []uint64{11, 12}), time.Now())
+	ts.CreateTeam(NewCreateTeamParam(10, []uint64{10}))
+	report, err = ts.ActivateSchedule(scheduleID)
+	if err != kOK || report.LeaderID != 11 || !reflect.DeepEqual(report.Dropped, GuidVector{10}) {
+		t.Errorf("ActivateSchedule() = %+v, %v, want leader %v and %v dropped", report, err, 11, 10)
+	}
+
+	// A leader who left the guild is dropped as well
+	players := NewLocalPlayerInfoProvider()
+	for _, guid := range []uint64{20, 21} {
+		players.Set(PlayerInfo{Guid: guid, GuildID: 5})
+	}
+	ts.SetPlayerInfoProvider(players, 0)
+	param := NewCreateTeamParam(20, []uint64{20, 21})
+	param.GuildID = 5
+	scheduleID, _ = ts.ScheduleTeam(param, time.Now())
+	players.Set(PlayerInfo{Guid: 20})
+	report, err = ts.ActivateSchedule(scheduleID)
+	if err != kOK || report.LeaderID != 21 || !reflect.DeepEqual(report.Dropped, GuidVector{20}) || !reflect.DeepEqual(report.Members, GuidVector{21}) {
+		t.Errorf("ActivateSchedule() = %+v, %v, want leader %v and %v dropped", report, err, 21, 20)
+	}
+}
+
+func TestActivateDueSchedules(t *testing.T) {
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ts := NewTeamSystem()
+	ts.SetClock(func() time.Time { return now })
+	later, _ := ts.ScheduleTeam(NewCreateTeamParam(200, []uint64{200}), now.Add(time.Hour))
+	second, _ := ts.ScheduleTeam(NewCreateTeamParam(100, []uint64{100}), now)
+	first, _ := ts.ScheduleTeam(NewCreateTeamParam(101, []uint64{101}), now.Add(-time.Minute))
+
+	reports := ts.ActivateDueSchedules()
+	if len(reports) != 2 || reports[0].LeaderID != 101 || reports[1].LeaderID != 100 {
+		t.Fatalf("ActivateDueSchedules() = %+v, want the rosters of %v and %v in start order", reports, first, second)
+	}
+	if _, ok := ts.ScheduledTeamOf(later); !ok {
+		t.Errorf("ScheduledTeamOf() of a later roster = false, want true")
+	}
+
+	data, err := json.Marshal(ts.Snapshot())
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var snapshot SystemSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	restored := RestoreTeamSystem(snapshot)
+	got, _ := restored.ScheduledTeamOf(later)
+	want, _ := ts.ScheduledTeamOf(later)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored ScheduledTeamOf() = %+v, want %+v", got, want)
+	}
+	if id, _ := restored.ScheduleTeam(NewCreateTeamParam(300, []uint64{300}), now); id != first+1 {
+		t.Errorf("ScheduleTeam() after restore = %v, want %v", id, first+1)
+	}
+}
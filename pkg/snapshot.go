@@ -0,0 +1,67 @@
+package pkg
+
+import "sort"
+
+// TeamSnapshot is a serializable copy of one team
+type TeamSnapshot struct {
+	ID           uint64       `json:"id"`
+	LeaderID     uint64       `json:"leader_id"`
+	MemberList   MemberList   `json:"member_list"`
+	Applicants   GuidVector   `json:"applicants"`
+	TeamTypeSize uint64       `json:"team_type_size"`
+	Raid         bool         `json:"raid,omitempty"`
+	Version      uint64       `json:"version"`
+	GuildID      uint64       `json:"guild_id,omitempty"`
+	Settings     TeamSettings `json:"settings"`
+
+	AutoAccept []AutoAcceptRule `json:"auto_accept,omitempty"`
+}
+
+// SystemSnapshot is a serializable copy of every team in the system
+type SystemSnapshot struct {
+	LastTeamID     uint64          `json:"last_team_id"`
+	Teams          []TeamSnapshot  `json:"teams"` // Ordered by ID
+	LastScheduleID uint64          `json:"last_schedule_id,omitempty"`
+	Schedules      []ScheduledTeam `json:"schedules,omitempty"` // Ordered by ID
+}
+
+// Snapshot copies the state of every team
+func (ts *TeamSystem) Snapshot() SystemSnapshot {
+	snapshot := SystemSnapshot{
+		LastTeamID: ts.lastTeamID,
+		Teams:      make([]TeamSnapshot, 0, len(ts.teams)),
+	}
+	for teamID := range ts.teams {
+		team, _ := ts.TeamSnapshot(teamID)
+		snapshot.Teams = append(snapshot.Teams, team)
+	}
+	sort.Slice(snapshot.Teams, func(i, j int) bool { return snapshot.Teams[i].ID < snapshot.Teams[j].ID })
+	for _, schedule := range ts.schedules {
+		snapshot.Schedules = append(snapshot.Schedules, schedule.clone())
+	}
+	sort.Slice(snapshot.Schedules, func(i, j int) bool { return snapshot.Schedules[i].ID < snapshot.Schedules[j].ID })
+	snapshot.LastScheduleID = ts.lastScheduleID
+	return snapshot
+}
+
+// TeamSnapshot copies the state of one team
+func (ts *TeamSystem) TeamSnapshot(teamID uint64) (TeamSnapshot, bool) {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return TeamSnapshot{}, false
+	}
+	settings, _ := ts.TeamSettingsOf(teamID)
+	snapshot := TeamSnapshot{
+		ID:           team.ID,
+		LeaderID:     team.LeaderID,
+		MemberList:   team.MemberList.clone(),
+		Applicants:   append(GuidVector{}, team.Applicants...),
+		TeamTypeSize: team.TeamTypeSize,
+		Raid:         ts.IsRaid(teamID),
+		Version:      team.Version,
+		GuildID:      team.GuildID,
+		Settings:     settings,
+		AutoAccept:   ts.AutoAcceptRulesOf(teamID),
+	}
+	return snapshot, true
+}
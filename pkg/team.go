@@ -1,6 +1,11 @@
 package pkg
 
-import "sync"
+import (
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+)
 
 // Constants
 const (
@@ -35,6 +40,28 @@ const (
 	kTeamAppointNotLeader        = 5019
 	kTeamApplyJoin               = 5020
 	kTeamApplyListFull           = 5021
+	kTeamNotRaid                 = 5022
+	kTeamRaidNotAssistant        = 5023
+	kTeamRaidSubGroupIndex       = 5024
+	kTeamRaidSubGroupFull        = 5025
+	kTeamLootNotLeader           = 5026
+	kTeamLootMethod              = 5027
+	kTeamRewardSplitRule         = 5028
+	kTeamLockoutConflict         = 5029
+	kTeamPlayerBlocked           = 5030
+	kTeamSettingsNotAuthorized   = 5031
+	kTeamSettingsVersionConflict = 5032
+	kTeamContextDone             = 5033
+	kTeamVersionConflict         = 5034
+	kTeamRateLimited             = 5035
+	kTeamTooManyApplications     = 5036
+	kTeamNotInGuild              = 5037
+	kTeamScheduleNotFound        = 5038
+	kTeamRewardOverflow          = 5039
+	kTeamScheduleSignedUp        = 5040
+	kTeamScheduleNotSignedUp     = 5041
+	kTeamScheduleLeader          = 5042
+	kTeamScheduleEmpty           = 5043
 )
 
 // GuidVector is a slice of Guid (uint64)
@@ -45,15 +72,19 @@ type CreateTeamParam struct {
 	LeaderID     uint64
 	MemberList   GuidVector
 	TeamTypeSize uint64
+	GuildID      uint64 // Makes the team guild-only when set
 }
 
 // Team represents a team entity
 type Team struct {
 	LeaderID     uint64
 	ID           uint64 // Assuming ID is uint64
-	MemberList   GuidVector
+	MemberList   MemberList
 	Applicants   GuidVector
 	TeamTypeSize uint64
+	CreatedAt    time.Time
+	Version      uint64 // Bumped on every change, never zero
+	GuildID      uint64 // Guild owning the team, only its members may join. kInvalidGuid for open teams
 }
 
 // TeamSystem represents the system managing teams
@@ -61,6 +92,43 @@ type TeamSystem struct {
 	teams       map[uint64]*Team // Map of team ID to Team
 	playerLists sync.Map         // Map of player ID to team ID
 	lastTeamID  uint64           // For testing
+	clock       func() time.Time
+	raids       map[uint64]*Raid // Map of team ID to raid layout
+
+	chats           map[uint64]*teamChannel   // Map of team ID to chat channel
+	chatSubscribers map[uint64]ChatSubscriber // Map of player ID to chat delivery
+
+	loots    map[uint64]*teamLoot // Map of team ID to loot settings
+	lootRand *rand.Rand
+
+	playerLockouts map[uint64]map[uint64]LockoutRecord // Map of player ID to lockouts by activity
+	teamLockouts   map[uint64]map[uint64]LockoutRecord // Map of team ID to lockouts by activity
+	teamInstances  map[uint64]uint64                   // Map of team ID to the activity it is in
+
+	relations RelationProvider
+
+	settings      map[uint64]*TeamSettings // Map of team ID to team settings
+	eventHandlers []func(TeamEvent)
+
+	audit    *auditLog
+	metrics  *teamMetrics
+	log      *opLogger
+	tracer   Tracer
+	journal  *opJournal
+	requests *requestCache
+	limiter  *rateLimiter
+
+	applications    map[uint64]GuidVector // Map of player ID to the teams applied to
+	maxApplications int
+	applicantPolicy ApplicantPolicy
+	applicantScorer ApplicantScorer
+	autoAccept      map[uint64][]AutoAcceptRule // Map of team ID to auto-accept rules
+
+	playerInfo *playerInfoCache
+	guilds     GuildMembership
+
+	schedules      map[uint64]*ScheduledTeam // Map of schedule ID to roster
+	lastScheduleID uint64
 }
 
 func NewCreateTeamParam(leaderID uint64, members []uint64, teamTypeSize ...uint64) CreateTeamParam {
@@ -78,10 +146,42 @@ func NewCreateTeamParam(leaderID uint64, members []uint64, teamTypeSize ...uint6
 }
 
 // NewTeamSystem initializes a new TeamSystem
-func NewTeamSystem() *TeamSystem {
-	return &TeamSystem{
+func NewTeamSystem(opts ...TeamSystemOption) *TeamSystem {
+	ts := &TeamSystem{
 		teams: make(map[uint64]*Team),
+		clock: time.Now,
+		raids: make(map[uint64]*Raid),
+
+		chats:           make(map[uint64]*teamChannel),
+		chatSubscribers: make(map[uint64]ChatSubscriber),
+
+		loots:    make(map[uint64]*teamLoot),
+		lootRand: rand.New(rand.NewSource(kDefaultLootSeed)),
+
+		playerLockouts: make(map[uint64]map[uint64]LockoutRecord),
+		teamLockouts:   make(map[uint64]map[uint64]LockoutRecord),
+		teamInstances:  make(map[uint64]uint64),
+
+		settings: make(map[uint64]*TeamSettings),
+
+		audit:    newAuditLog(kAuditWindowSize),
+		metrics:  newTeamMetrics(),
+		log:      newOpLogger(),
+		tracer:   noopTracer{},
+		journal:  &opJournal{},
+		requests: newRequestCache(kRequestCacheSize),
+		limiter:  newRateLimiter(),
+
+		applications: make(map[uint64]GuidVector),
+		autoAccept:   make(map[uint64][]AutoAcceptRule),
+		playerInfo:   &playerInfoCache{entries: make(map[uint64]playerInfoEntry)},
+
+		schedules: make(map[uint64]*ScheduledTeam),
 	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	return ts
 }
 
 // Methods of TeamSystem
@@ -99,14 +199,16 @@ func (ts *TeamSystem) IsTeamListMax() bool {
 }
 
 func (ts *TeamSystem) MemberSize(teamID uint64) int {
+	size := 0
 	if team, ok := ts.teams[teamID]; ok {
-		return len(team.MemberList)
+		size = len(team.MemberList)
 	}
-	return 0
+	ts.log.logQuery("MemberSize", slog.Uint64("team_id", teamID), slog.Int("size", size))
+	return size
 }
 
 func (ts *TeamSystem) ApplicantSizeByPlayerID(guid uint64) int {
-	teamID := ts.GetTeamID(guid)
+	teamID := ts.teamIDOf(guid)
 	return ts.ApplicantSizeByTeamID(teamID)
 }
 
@@ -127,6 +229,12 @@ func (ts *TeamSystem) PlayersSize() int {
 }
 
 func (ts *TeamSystem) GetTeamID(guid uint64) uint64 {
+	teamID := ts.teamIDOf(guid)
+	ts.log.logQuery("GetTeamID", slog.Uint64("guid", guid), slog.Uint64("team_id", teamID))
+	return teamID
+}
+
+func (ts *TeamSystem) teamIDOf(guid uint64) uint64 {
 	if teamID, ok := ts.playerLists.Load(guid); ok {
 		return teamID.(uint64)
 	}
@@ -141,8 +249,9 @@ func (ts *TeamSystem) GetLeaderIDByTeamID(teamID uint64) uint64 {
 }
 
 func (ts *TeamSystem) GetLeaderIDByPlayerID(guid uint64) uint64 {
-	teamID := ts.GetTeamID(guid)
-	return ts.GetLeaderIDByTeamID(teamID)
+	leaderID := ts.GetLeaderIDByTeamID(ts.teamIDOf(guid))
+	ts.log.logQuery("GetLeaderIDByPlayerID", slog.Uint64("guid", guid), slog.Uint64("leader_id", leaderID))
+	return leaderID
 }
 
 func (ts *TeamSystem) FirstApplicant(teamID uint64) uint64 {
@@ -161,11 +270,7 @@ func (ts *TeamSystem) IsTeamFull(teamID uint64) bool {
 
 func (ts *TeamSystem) HasMember(teamID, guid uint64) bool {
 	if team, ok := ts.teams[teamID]; ok {
-		for _, member := range team.MemberList {
-			if member == guid {
-				return true
-			}
-		}
+		return team.MemberList.Index(guid) != -1
 	}
 	return false
 }
@@ -187,6 +292,16 @@ func (ts *TeamSystem) IsApplicant(teamID, guid uint64) bool {
 }
 
 func (ts *TeamSystem) CreateTeam(param CreateTeamParam) uint32 {
+	call := ts.beginOp(OpCreateTeam, kInvalidGuid, param.LeaderID, kInvalidGuid)
+	call.members, call.typeSize, call.guildID = param.MemberList, param.TeamTypeSize, param.GuildID
+	result := ts.createTeam(param)
+	if result == kOK {
+		call.teamID = ts.lastTeamID
+	}
+	return ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) createTeam(param CreateTeamParam) uint32 {
 	// Check if the team list has reached its maximum size
 	if ts.IsTeamListMax() {
 		return kTeamListMaxSize
@@ -205,6 +320,14 @@ func (ts *TeamSystem) CreateTeam(param CreateTeamParam) uint32 {
 		return err
 	}
 
+	// Check that a guild-only team is formed by members of the guild
+	if param.GuildID != kInvalidGuid {
+		members := append(GuidVector{param.LeaderID}, param.MemberList...)
+		if len(ts.guildMembers(param.GuildID, members)) != len(members) {
+			return kTeamNotInGuild
+		}
+	}
+
 	// Create a new team with a new ID
 	teamID := ts.lastTeamID + 1
 	ts.lastTeamID = teamID
@@ -213,22 +336,37 @@ func (ts *TeamSystem) CreateTeam(param CreateTeamParam) uint32 {
 	team := &Team{
 		LeaderID:     param.LeaderID,
 		ID:           teamID,
-		MemberList:   make(GuidVector, len(param.MemberList)),
+		MemberList:   make(MemberList, 0, len(param.MemberList)),
 		Applicants:   make(GuidVector, 0),
 		TeamTypeSize: param.TeamTypeSize,
+		CreatedAt:    ts.now(),
+		Version:      1,
+		GuildID:      param.GuildID,
 	}
-	copy(team.MemberList, param.MemberList)
 	ts.teams[teamID] = team
+	ts.onTeamCreated(team)
 
-	// Update player to team mappings
+	// Add the members and update player to team mappings
 	for _, member := range param.MemberList {
-		ts.playerLists.Store(member, teamID)
+		ts.addMember(team, member, JoinCreated)
 	}
 
 	return kOK
 }
 
 func (ts *TeamSystem) JoinTeam(teamID, guid uint64) uint32 {
+	call := ts.beginOp(OpJoinTeam, teamID, kInvalidGuid, guid)
+	if err := ts.checkRateLimit(OpJoinTeam, teamID, guid); err != kOK {
+		return ts.endOp(call, err)
+	}
+	method := JoinDirect
+	if ts.IsApplicant(teamID, guid) {
+		method = JoinApplied
+	}
+	return ts.endOp(call, ts.joinTeam(teamID, guid, method))
+}
+
+func (ts *TeamSystem) joinTeam(teamID, guid uint64, method JoinMethod) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		if ts.HasTeam(guid) {
 			return kTeamMemberInTeam
@@ -236,17 +374,31 @@ func (ts *TeamSystem) JoinTeam(teamID, guid uint64) uint32 {
 		if ts.IsTeamFull(teamID) {
 			return kTeamMembersFull
 		}
+		if err := ts.CheckLockout(teamID, guid); err != kOK {
+			return err
+		}
+		if ts.IsBlockedByTeam(teamID, guid) {
+			return kTeamPlayerBlocked
+		}
+		if err := ts.checkGuild(team, guid); err != kOK {
+			return err
+		}
 		if idx := ts.FindApplicantIndex(team, guid); idx != -1 {
-			team.Applicants = append(team.Applicants[:idx], team.Applicants[idx+1:]...)
+			ts.removeApplicantAt(team, idx)
 		}
-		team.MemberList = append(team.MemberList, guid)
-		ts.playerLists.Store(guid, teamID)
+		ts.addMember(team, guid, method) // Bumps the version and withdraws other applications
 		return kOK
 	}
 	return kTeamHasNotTeamId
 }
 
 func (ts *TeamSystem) JoinTeamByMemberList(memberList GuidVector, teamID uint64) uint32 {
+	call := ts.beginOp(OpJoinTeamByMemberList, teamID, kInvalidGuid, kInvalidGuid)
+	call.members = memberList
+	return ts.endOp(call, ts.joinTeamByMemberList(memberList, teamID))
+}
+
+func (ts *TeamSystem) joinTeamByMemberList(memberList GuidVector, teamID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		if len(team.MemberList)+len(memberList) > int(team.TeamTypeSize) {
 			return kTeamJoinTeamMemberListToMax
@@ -255,7 +407,20 @@ func (ts *TeamSystem) JoinTeamByMemberList(memberList GuidVector, teamID uint64)
 			return err
 		}
 		for _, member := range memberList {
-			if err := ts.JoinTeam(teamID, member); err != kOK {
+			if err := ts.CheckLockout(teamID, member); err != kOK {
+				return err
+			}
+		}
+		if err := ts.checkBlockedMemberList(teamID, memberList); err != kOK {
+			return err
+		}
+		for _, member := range memberList {
+			if err := ts.checkGuild(team, member); err != kOK {
+				return err
+			}
+		}
+		for _, member := range memberList {
+			if err := ts.joinTeam(teamID, member, JoinMatchmade); err != kOK {
 				return err
 			}
 		}
@@ -274,7 +439,12 @@ func (ts *TeamSystem) CheckMemberInTeam(memberList GuidVector) uint32 {
 }
 
 func (ts *TeamSystem) LeaveTeam(guid uint64) uint32 {
-	teamID := ts.GetTeamID(guid)
+	call := ts.beginOp(OpLeaveTeam, ts.teamIDOf(guid), guid, guid)
+	return ts.endOp(call, ts.leaveTeam(guid))
+}
+
+func (ts *TeamSystem) leaveTeam(guid uint64) uint32 {
+	teamID := ts.teamIDOf(guid)
 	if team, ok := ts.teams[teamID]; ok {
 		if !ts.HasMember(teamID, guid) {
 			return kTeamMemberNotInTeam
@@ -282,7 +452,7 @@ func (ts *TeamSystem) LeaveTeam(guid uint64) uint32 {
 		isLeaderLeave := team.LeaderID == guid
 		ts.DelMember(teamID, guid)
 		if len(team.MemberList) > 0 && isLeaderLeave {
-			ts.OnAppointLeader(teamID, team.MemberList[0])
+			ts.OnAppointLeader(teamID, team.MemberList[0].Guid)
 		}
 		if len(team.MemberList) == 0 {
 			ts.EraseTeam(teamID)
@@ -293,6 +463,14 @@ func (ts *TeamSystem) LeaveTeam(guid uint64) uint32 {
 }
 
 func (ts *TeamSystem) KickMember(teamID, currentLeaderID, beKickID uint64) uint32 {
+	call := ts.beginOp(OpKickMember, teamID, currentLeaderID, beKickID)
+	if err := ts.checkRateLimit(OpKickMember, teamID, currentLeaderID); err != kOK {
+		return ts.endOp(call, err)
+	}
+	return ts.endOp(call, ts.kickMember(teamID, currentLeaderID, beKickID))
+}
+
+func (ts *TeamSystem) kickMember(teamID, currentLeaderID, beKickID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		if team.LeaderID != currentLeaderID {
 			return kTeamKickNotLeader
@@ -310,12 +488,17 @@ func (ts *TeamSystem) KickMember(teamID, currentLeaderID, beKickID uint64) uint3
 }
 
 func (ts *TeamSystem) Disbanded(teamID, currentLeaderID uint64) uint32 {
+	call := ts.beginOp(OpDisbanded, teamID, currentLeaderID, kInvalidGuid)
+	return ts.endOp(call, ts.disbanded(teamID, currentLeaderID))
+}
+
+func (ts *TeamSystem) disbanded(teamID, currentLeaderID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		if team.LeaderID != currentLeaderID {
 			return kTeamDismissNotLeader
 		}
 		for _, member := range team.MemberList {
-			ts.DelMember(teamID, member)
+			ts.DelMember(teamID, member.Guid)
 		}
 		ts.EraseTeam(teamID)
 		return kOK
@@ -323,14 +506,25 @@ func (ts *TeamSystem) Disbanded(teamID, currentLeaderID uint64) uint32 {
 	return kTeamHasNotTeamId
 }
 
+// DisbandedTeamNoLeader disbands the team on behalf of the system, the leader is audited as the target
 func (ts *TeamSystem) DisbandedTeamNoLeader(teamID uint64) uint32 {
+	call := ts.beginOp(OpDisbandedTeamNoLeader, teamID, kInvalidGuid, ts.GetLeaderIDByTeamID(teamID))
+	return ts.endOp(call, ts.disbandedTeamNoLeader(teamID))
+}
+
+func (ts *TeamSystem) disbandedTeamNoLeader(teamID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
-		return ts.Disbanded(teamID, team.LeaderID)
+		return ts.disbanded(teamID, team.LeaderID)
 	}
 	return kTeamHasNotTeamId
 }
 
 func (ts *TeamSystem) AppointLeader(teamID, currentLeaderID, newLeaderID uint64) uint32 {
+	call := ts.beginOp(OpAppointLeader, teamID, currentLeaderID, newLeaderID)
+	return ts.endOp(call, ts.appointLeader(teamID, currentLeaderID, newLeaderID))
+}
+
+func (ts *TeamSystem) appointLeader(teamID, currentLeaderID, newLeaderID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		if team.LeaderID == newLeaderID {
 			return kTeamAppointSelf
@@ -348,6 +542,14 @@ func (ts *TeamSystem) AppointLeader(teamID, currentLeaderID, newLeaderID uint64)
 }
 
 func (ts *TeamSystem) ApplyToTeam(teamID, guid uint64) uint32 {
+	call := ts.beginOp(OpApplyToTeam, teamID, guid, guid)
+	if err := ts.checkRateLimit(OpApplyToTeam, teamID, guid); err != kOK {
+		return ts.endOp(call, err)
+	}
+	return ts.endOp(call, ts.applyToTeam(teamID, guid))
+}
+
+func (ts *TeamSystem) applyToTeam(teamID, guid uint64) uint32 {
 	team, ok := ts.teams[teamID]
 	if !ok {
 		// Team with teamID does not exist
@@ -368,27 +570,55 @@ func (ts *TeamSystem) ApplyToTeam(teamID, guid uint64) uint32 {
 		return kTeamMembersFull
 	}
 
+	// Check if the user is locked to another instance of the team's activity
+	if err := ts.CheckLockout(teamID, guid); err != kOK {
+		return err
+	}
+
+	// Check if the user and any member have blocked each other
+	if ts.IsBlockedByTeam(teamID, guid) {
+		return kTeamPlayerBlocked
+	}
+
+	// Check if the user belongs to the guild of a guild-only team
+	if err := ts.checkGuild(team, guid); err != kOK {
+		return err
+	}
+
 	// Check if the user is already an applicant
 	if ts.IsApplicant(teamID, guid) {
 		return kTeamApplyJoin
 	}
 
-	// If the applicants list is full, remove the oldest applicant
-	if len(team.Applicants) >= kMaxApplicantSize {
-		// Remove the first applicant from the list
-		team.Applicants = team.Applicants[1:]
+	// Take the user in directly if the team auto-accepts them, no application is created
+	if ts.autoAccepts(teamID, guid) {
+		return ts.joinTeam(teamID, guid, JoinAutoAccepted)
+	}
+
+	// Check if the user has reached the cap of outstanding applications
+	if ts.maxApplications > 0 && len(ts.applications[guid]) >= ts.maxApplications {
+		return kTeamTooManyApplications
 	}
 
-	// Add the user to the applicant list
-	team.Applicants = append(team.Applicants, guid)
+	// Add the user to the applicant list, making room according to the applicant policy
+	if err := ts.addApplicant(team, guid); err != kOK {
+		return err
+	}
+	team.Version++
 	return kOK
 }
 
 func (ts *TeamSystem) DelApplicant(teamID, guid uint64) uint32 {
+	call := ts.beginOp(OpDelApplicant, teamID, kInvalidGuid, guid)
+	return ts.endOp(call, ts.delApplicant(teamID, guid))
+}
+
+func (ts *TeamSystem) delApplicant(teamID, guid uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
 		for idx, applicant := range team.Applicants {
 			if applicant == guid {
-				team.Applicants = append(team.Applicants[:idx], team.Applicants[idx+1:]...)
+				ts.removeApplicantAt(team, idx)
+				team.Version++
 				return kOK
 			}
 		}
@@ -397,8 +627,15 @@ func (ts *TeamSystem) DelApplicant(teamID, guid uint64) uint32 {
 }
 
 func (ts *TeamSystem) ClearApplyList(teamID uint64) uint32 {
+	call := ts.beginOp(OpClearApplyList, teamID, kInvalidGuid, kInvalidGuid)
+	return ts.endOp(call, ts.clearApplyList(teamID))
+}
+
+func (ts *TeamSystem) clearApplyList(teamID uint64) uint32 {
 	if team, ok := ts.teams[teamID]; ok {
+		ts.applicationsTeamErased(team)
 		team.Applicants = make(GuidVector, 0)
+		team.Version++
 		return kOK
 	}
 	return kTeamHasNotTeamId
@@ -407,20 +644,19 @@ func (ts *TeamSystem) ClearApplyList(teamID uint64) uint32 {
 func (ts *TeamSystem) EraseTeam(teamID uint64) {
 	if team, ok := ts.teams[teamID]; ok {
 		for _, member := range team.MemberList {
-			ts.playerLists.Delete(member)
+			ts.playerLists.Delete(member.Guid)
 		}
 		delete(ts.teams, teamID)
+		ts.onTeamErased(team)
 	}
 }
 
 func (ts *TeamSystem) DelMember(teamID, guid uint64) {
 	if team, ok := ts.teams[teamID]; ok {
-		for idx, member := range team.MemberList {
-			if member == guid {
-				team.MemberList = append(team.MemberList[:idx], team.MemberList[idx+1:]...)
-				ts.playerLists.Delete(guid)
-				return
-			}
+		if idx := team.MemberList.Index(guid); idx != -1 {
+			team.MemberList = append(team.MemberList[:idx], team.MemberList[idx+1:]...)
+			ts.playerLists.Delete(guid)
+			ts.onMemberRemoved(team, guid)
 		}
 	}
 }
@@ -428,6 +664,7 @@ func (ts *TeamSystem) DelMember(teamID, guid uint64) {
 func (ts *TeamSystem) OnAppointLeader(teamID, newLeaderID uint64) {
 	if team, ok := ts.teams[teamID]; ok {
 		team.LeaderID = newLeaderID
+		team.Version++
 	}
 }
 
@@ -439,3 +676,59 @@ func (ts *TeamSystem) FindApplicantIndex(team *Team, guid uint64) int {
 	}
 	return -1
 }
+
+// addMember appends the member record and updates the player to team mapping
+func (ts *TeamSystem) addMember(team *Team, guid uint64, method JoinMethod) {
+	team.MemberList = append(team.MemberList, TeamMember{
+		Guid:       guid,
+		JoinedAt:   ts.now(),
+		JoinMethod: method,
+	})
+	ts.playerLists.Store(guid, team.ID)
+	ts.onMemberJoined(team, guid)
+}
+
+// teamChanged bumps the version of the team after a change made outside the hooks below
+func (ts *TeamSystem) teamChanged(teamID uint64) {
+	if team, ok := ts.teams[teamID]; ok {
+		team.Version++
+	}
+}
+
+// onTeamCreated is called after the team has been added to the system, before its members
+func (ts *TeamSystem) onTeamCreated(team *Team) {
+	ts.metrics.teamCreated(team)
+}
+
+// onMemberJoined is called after guid has been added to team.MemberList
+func (ts *TeamSystem) onMemberJoined(team *Team, guid uint64) {
+	team.Version++
+	ts.applicationsMemberJoined(guid)
+	ts.raidMemberJoined(team, guid)
+	ts.chatMemberJoined(team, guid)
+	ts.lootMemberJoined(team, guid)
+	ts.lockoutMemberJoined(team, guid)
+	ts.metrics.memberJoined(team)
+}
+
+// onMemberRemoved is called after guid has been removed from team.MemberList
+func (ts *TeamSystem) onMemberRemoved(team *Team, guid uint64) {
+	team.Version++
+	ts.raidMemberRemoved(team, guid)
+	ts.chatMemberRemoved(team, guid)
+	ts.lootMemberRemoved(team, guid)
+	ts.metrics.memberRemoved(team)
+}
+
+// onTeamErased is called after the team has been deleted from the system
+func (ts *TeamSystem) onTeamErased(team *Team) {
+	ts.applicationsTeamErased(team)
+	delete(ts.raids, team.ID)
+	delete(ts.chats, team.ID)
+	delete(ts.loots, team.ID)
+	delete(ts.teamLockouts, team.ID)
+	delete(ts.teamInstances, team.ID)
+	delete(ts.settings, team.ID)
+	delete(ts.autoAccept, team.ID)
+	ts.metrics.teamErased(team, ts.now())
+}
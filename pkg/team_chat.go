@@ -0,0 +1,111 @@
+package pkg
+
+import "time"
+
+// kTeamChatHistorySize is the number of messages kept per team channel
+const kTeamChatHistorySize = 50
+
+// ChatMessage represents one message posted to a team channel
+type ChatMessage struct {
+	Seq    uint64
+	TeamID uint64
+	Sender uint64
+	Text   string
+	SentAt time.Time
+}
+
+// ChatSubscriber receives the messages delivered to a player
+type ChatSubscriber func(receiver uint64, msg ChatMessage)
+
+// teamChannel keeps the members and bounded history of a team channel
+type teamChannel struct {
+	members map[uint64]struct{}
+	history *ring[ChatMessage]
+	lastSeq uint64
+}
+
+func newTeamChannel() *teamChannel {
+	return &teamChannel{
+		members: make(map[uint64]struct{}),
+		history: newRing[ChatMessage](kTeamChatHistorySize),
+	}
+}
+
+// SubscribeTeamChat registers the callback used to deliver team messages to guid
+func (ts *TeamSystem) SubscribeTeamChat(guid uint64, subscriber ChatSubscriber) {
+	ts.chatSubscribers[guid] = subscriber
+}
+
+func (ts *TeamSystem) UnsubscribeTeamChat(guid uint64) {
+	delete(ts.chatSubscribers, guid)
+}
+
+func (ts *TeamSystem) IsInTeamChat(teamID, guid uint64) bool {
+	if ch, ok := ts.chats[teamID]; ok {
+		_, in := ch.members[guid]
+		return in
+	}
+	return false
+}
+
+// SendTeamChat posts text to the team channel and delivers it to subscribed members
+func (ts *TeamSystem) SendTeamChat(teamID, sender uint64, text string) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if !ts.HasMember(teamID, sender) {
+		return kTeamMemberNotInTeam
+	}
+
+	ch := ts.teamChannel(teamID)
+	ch.lastSeq++
+	msg := ChatMessage{
+		Seq:    ch.lastSeq,
+		TeamID: teamID,
+		Sender: sender,
+		Text:   text,
+		SentAt: ts.now(),
+	}
+	ch.history.push(msg)
+
+	for _, member := range team.MemberList {
+		if _, in := ch.members[member.Guid]; !in {
+			continue
+		}
+		if subscriber, ok := ts.chatSubscribers[member.Guid]; ok {
+			subscriber(member.Guid, msg)
+		}
+	}
+	return kOK
+}
+
+// TeamChatHistory returns the retained messages of the team, oldest first
+func (ts *TeamSystem) TeamChatHistory(teamID, guid uint64) ([]ChatMessage, uint32) {
+	if _, ok := ts.teams[teamID]; !ok {
+		return nil, kTeamHasNotTeamId
+	}
+	if !ts.HasMember(teamID, guid) {
+		return nil, kTeamMemberNotInTeam
+	}
+	return ts.teamChannel(teamID).history.all(), kOK
+}
+
+func (ts *TeamSystem) teamChannel(teamID uint64) *teamChannel {
+	ch, ok := ts.chats[teamID]
+	if !ok {
+		ch = newTeamChannel()
+		ts.chats[teamID] = ch
+	}
+	return ch
+}
+
+func (ts *TeamSystem) chatMemberJoined(team *Team, guid uint64) {
+	ts.teamChannel(team.ID).members[guid] = struct{}{}
+}
+
+func (ts *TeamSystem) chatMemberRemoved(team *Team, guid uint64) {
+	if ch, ok := ts.chats[team.ID]; ok {
+		delete(ch.members, guid)
+	}
+}
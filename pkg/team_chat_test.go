@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestTeamChatDelivery(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(100)
+
+	if got := ts.CreateTeam(NewCreateTeamParam(leaderID, []uint64{leaderID, 101})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	received := make(map[uint64][]string)
+	for _, guid := range []uint64{leaderID, 101, 102} {
+		ts.SubscribeTeamChat(guid, func(receiver uint64, msg ChatMessage) {
+			received[receiver] = append(received[receiver], msg.Text)
+		})
+	}
+
+	if got := ts.SendTeamChat(teamID, 102, "hello"); got != kTeamMemberNotInTeam {
+		t.Errorf("SendTeamChat() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	if got := ts.SendTeamChat(teamID, leaderID, "hello"); got != kOK {
+		t.Errorf("SendTeamChat() = %v, want %v", got, kOK)
+	}
+	if got := ts.JoinTeam(teamID, 102); got != kOK {
+		t.Errorf("JoinTeam() = %v, want %v", got, kOK)
+	}
+	if !ts.IsInTeamChat(teamID, 102) {
+		t.Errorf("Expected joined member to be in team chat")
+	}
+	if got := ts.KickMember(teamID, leaderID, 101); got != kOK {
+		t.Errorf("KickMember() = %v, want %v", got, kOK)
+	}
+	if ts.IsInTeamChat(teamID, 101) {
+		t.Errorf("Expected kicked member to leave team chat")
+	}
+	if got := ts.SendTeamChat(teamID, 102, "world"); got != kOK {
+		t.Errorf("SendTeamChat() = %v, want %v", got, kOK)
+	}
+
+	if got := len(received[leaderID]); got != 2 {
+		t.Errorf("leader received %v messages, want %v", got, 2)
+	}
+	if got := len(received[101]); got != 1 {
+		t.Errorf("kicked member received %v messages, want %v", got, 1)
+	}
+	if got := len(received[102]); got != 1 {
+		t.Errorf("new member received %v messages, want %v", got, 1)
+	}
+
+	if _, got := ts.TeamChatHistory(teamID, 101); got != kTeamMemberNotInTeam {
+		t.Errorf("TeamChatHistory() = %v, want %v", got, kTeamMemberNotInTeam)
+	}
+	history, got := ts.TeamChatHistory(teamID, 102)
+	if got != kOK {
+		t.Errorf("TeamChatHistory() = %v, want %v", got, kOK)
+	}
+	if len(history) != 2 || history[0].Text != "hello" || history[1].Text != "world" {
+		t.Errorf("TeamChatHistory() = %v, want [hello world]", history)
+	}
+
+	if got := ts.Disbanded(teamID, leaderID); got != kOK {
+		t.Errorf("Disbanded() = %v, want %v", got, kOK)
+	}
+	if _, ok := ts.chats[teamID]; ok {
+		t.Errorf("Expected team chat to be dropped with the team")
+	}
+}
+
+func TestTeamChatHistoryBounded(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(100)
+
+	if got := ts.CreateTeam(NewCreateTeamParam(leaderID, []uint64{leaderID})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	nMax := kTeamChatHistorySize * 2
+	for i := 0; i < nMax; i++ {
+		if got := ts.SendTeamChat(teamID, leaderID, "msg"); got != kOK {
+			t.Errorf("SendTeamChat() = %v, want %v", got, kOK)
+		}
+	}
+
+	history, _ := ts.TeamChatHistory(teamID, leaderID)
+	if got := len(history); got != kTeamChatHistorySize {
+		t.Errorf("TeamChatHistory() size = %v, want %v", got, kTeamChatHistorySize)
+	}
+	for i, msg := range history {
+		if want := uint64(nMax - kTeamChatHistorySize + i + 1); msg.Seq != want {
+			t.Errorf("history[%v].Seq = %v, want %v", i, msg.Seq, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package pkg
+
+// TeamSettings represents the metadata game modes attach to a team
+type TeamSettings struct {
+	ActivityID  uint64            `json:"activity_id"`
+	Difficulty  uint32            `json:"difficulty"`
+	Description string            `json:"description"`
+	Custom      map[string]string `json:"custom,omitempty"` // Flags owned by game modes
+	Version     uint64            `json:"version"`          // Bumped on every successful update
+}
+
+func (s TeamSettings) clone() TeamSettings {
+	if s.Custom != nil {
+		custom := make(map[string]string, len(s.Custom))
+		for key, value := range s.Custom {
+			custom[key] = value
+		}
+		s.Custom = custom
+	}
+	return s
+}
+
+// TeamSettingsOf returns a copy of the settings of the team
+func (ts *TeamSystem) TeamSettingsOf(teamID uint64) (TeamSettings, bool) {
+	if _, ok := ts.teams[teamID]; !ok {
+		return TeamSettings{}, false
+	}
+	if settings, ok := ts.settings[teamID]; ok {
+		return settings.clone(), true
+	}
+	return TeamSettings{}, true
+}
+
+// UpdateTeamSettings applies update to the settings of the team. version must be the
+// version the editor last read, otherwise kTeamSettingsVersionConflict is returned.
+// Only the leader and raid assistants may edit the settings.
+func (ts *TeamSystem) UpdateTeamSettings(teamID, editorID, version uint64, update func(*TeamSettings)) uint32 {
+	call := ts.beginOp(OpUpdateTeamSettings, teamID, editorID, kInvalidGuid)
+	call.args = &OpArgs{SettingsVersion: version}
+	result := ts.updateTeamSettings(teamID, editorID, version, update)
+	if result == kOK {
+		settings, _ := ts.TeamSettingsOf(teamID)
+		call.args.Settings = &settings
+	}
+	return ts.endOp(call, result)
+}
+
+func (ts *TeamSystem) updateTeamSettings(teamID, editorID, version uint64, update func(*TeamSettings)) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if team.LeaderID != editorID && !ts.IsRaidAssistant(teamID, editorID) {
+		return kTeamSettingsNotAuthorized
+	}
+
+	current, _ := ts.TeamSettingsOf(teamID)
+	if current.Version != version {
+		return kTeamSettingsVersionConflict
+	}
+
+	update(&current)
+	current.Version = version + 1
+	ts.settings[teamID] = &current
+	ts.teamChanged(teamID)
+	ts.emit(TeamEvent{Type: TeamEventSettingsChanged, TeamID: teamID, Guid: editorID, Version: current.Version})
+	return kOK
+}
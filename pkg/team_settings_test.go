@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestUpdateTeamSettings(t *testing.T) {
+	ts := NewTeamSystem()
+	leaderID := uint64(100)
+
+	events := make([]TeamEvent, 0)
+	ts.SubscribeEvents(func(event TeamEvent) {
+		events = append(events, event)
+	})
+
+	if got := ts.CreateTeam(NewCreateTeamParam(leaderID, []uint64{leaderID, 101})); got != kOK {
+		t.Errorf("CreateTeam() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+
+	settings, ok := ts.TeamSettingsOf(teamID)
+	if !ok || settings.Version != 0 {
+		t.Errorf("TeamSettingsOf() = %v %v, want version 0", settings, ok)
+	}
+
+	setHeroic := func(s *TeamSettings) {
+		s.ActivityID = 7
+		s.Difficulty = 2
+		s.Description = "heroic run"
+		s.Custom = map[string]string{"voice": "required"}
+	}
+	if got := ts.UpdateTeamSettings(teamID, 101, 0, setHeroic); got != kTeamSettingsNotAuthorized {
+		t.Errorf("UpdateTeamSettings() = %v, want %v", got, kTeamSettingsNotAuthorized)
+	}
+	if got := ts.UpdateTeamSettings(teamID, leaderID, 0, setHeroic); got != kOK {
+		t.Errorf("UpdateTeamSettings() = %v, want %v", got, kOK)
+	}
+
+	// A second editor still holding version 0 must be rejected
+	if got := ts.UpdateTeamSettings(teamID, leaderID, 0, func(s *TeamSettings) { s.Difficulty = 1 }); got != kTeamSettingsVersionConflict {
+		t.Errorf("UpdateTeamSettings() = %v, want %v", got, kTeamSettingsVersionConflict)
+	}
+
+	settings, _ = ts.TeamSettingsOf(teamID)
+	if settings.Version != 1 || settings.Difficulty != 2 || settings.Custom["voice"] != "required" {
+		t.Errorf("TeamSettingsOf() = %v, want version 1 difficulty 2", settings)
+	}
+	settings.Custom["voice"] = "changed"
+	if settings, _ = ts.TeamSettingsOf(teamID); settings.Custom["voice"] != "required" {
+		t.Errorf("Expected TeamSettingsOf() to return a copy")
+	}
+
+	if len(events) != 1 || events[0] != (TeamEvent{Type: TeamEventSettingsChanged, TeamID: teamID, Guid: leaderID, Version: 1}) {
+		t.Errorf("events = %v, want one settings change", events)
+	}
+
+	snapshot := ts.Snapshot()
+	if len(snapshot.Teams) != 1 || snapshot.Teams[0].Settings.Description != "heroic run" {
+		t.Errorf("Snapshot() = %v, want team settings included", snapshot)
+	}
+
+	ts.Disbanded(teamID, leaderID)
+	if _, ok := ts.TeamSettingsOf(teamID); ok {
+		t.Errorf("Expected settings to be removed with the team")
+	}
+	if got := ts.UpdateTeamSettings(teamID, leaderID, 1, setHeroic); got != kTeamHasNotTeamId {
+		t.Errorf("UpdateTeamSettings() = %v, want %v", got, kTeamHasNotTeamId)
+	}
+}
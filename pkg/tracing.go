@@ -0,0 +1,446 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanAttribute is a key/value pair attached to a span
+type SpanAttribute struct {
+	Key   string
+	Value any
+}
+
+// Span is one traced operation, shaped after OpenTelemetry spans
+type Span interface {
+	SetAttributes(attrs ...SpanAttribute)
+	// SetStatus marks the span as failed when ok is false
+	SetStatus(ok bool, description string)
+	End()
+}
+
+// Tracer starts spans, adapters can forward to an OpenTelemetry tracer
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+// WithTracer traces the context-aware operations such as CreateTeamCtx with tracer, nil disables tracing
+func WithTracer(tracer Tracer) TeamSystemOption {
+	return func(ts *TeamSystem) {
+		if tracer == nil {
+			tracer = noopTracer{}
+		}
+		ts.tracer = tracer
+	}
+}
+
+type noopTracer struct{}
+
+type noopSpan struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...SpanAttribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) SetAttributes(...SpanAttribute) {}
+func (noopSpan) SetStatus(bool, string)         {}
+func (noopSpan) End()                           {}
+
+// traceOp runs op inside a span, or returns kTeamContextDone without running it once ctx is done.
+// A call carrying a request ID already seen for op returns the recorded result without running again.
+func (ts *TeamSystem) traceOp(ctx context.Context, op Op, attrs []SpanAttribute, run func(Span) uint32) uint32 {
+	_, result := traceCall(ts, ctx, op, attrs, func(span Span) (struct{}, uint32) {
+		return struct{}{}, run(span)
+	})
+	return result
+}
+
+// traceCall is traceOp for calls returning a value besides the result, a retry gets the recorded value
+func traceCall[T any](ts *TeamSystem, ctx context.Context, op Op, attrs []SpanAttribute, run func(Span) (T, uint32)) (T, uint32) {
+	requestID, hasRequestID := RequestIDFrom(ctx)
+	if hasRequestID {
+		attrs = append(attrs, SpanAttribute{Key: "team.request_id", Value: requestID})
+	}
+	ctx, span := ts.tracer.Start(ctx, "team."+string(op), attrs...)
+	defer span.End()
+
+	var value T
+	if err := ctx.Err(); err != nil {
+		span.SetAttributes(SpanAttribute{Key: "team.result", Value: uint64(kTeamContextDone)})
+		span.SetStatus(false, err.Error())
+		return value, kTeamContextDone
+	}
+
+	cached, retried := requestResult{}, false
+	if hasRequestID {
+		cached, retried = ts.requests.lookup(op, requestID)
+	}
+	result := cached.result
+	if retried {
+		span.SetAttributes(SpanAttribute{Key: "team.retried", Value: true})
+		if cached.teamID != kInvalidGuid {
+			span.SetAttributes(teamAttr(cached.teamID))
+		}
+		value, _ = cached.value.(T)
+	} else {
+		value, result = run(span)
+		if hasRequestID {
+			stored := requestResult{result: result, value: value}
+			if (op == OpCreateTeam || op == OpCreateRaid) && result == kOK {
+				stored.teamID = ts.lastTeamID
+			}
+			ts.requests.store(op, requestID, stored)
+		}
+	}
+
+	span.SetAttributes(SpanAttribute{Key: "team.result", Value: uint64(result)})
+	if result != kOK {
+		span.SetStatus(false, string(op)+" failed")
+	} else {
+		span.SetStatus(true, "")
+	}
+	return value, result
+}
+
+func teamAttr(teamID uint64) SpanAttribute {
+	return SpanAttribute{Key: "team.id", Value: teamID}
+}
+
+func playerAttr(key string, guid uint64) SpanAttribute {
+	return SpanAttribute{Key: "player." + key, Value: guid}
+}
+
+func versionAttr(version uint64) SpanAttribute {
+	return SpanAttribute{Key: "team.version", Value: version}
+}
+
+func membersAttr(members GuidVector) SpanAttribute {
+	return SpanAttribute{Key: "player.members", Value: append(GuidVector{}, members...)}
+}
+
+func scheduleAttr(scheduleID uint64) SpanAttribute {
+	return SpanAttribute{Key: "team.schedule_id", Value: scheduleID}
+}
+
+func (ts *TeamSystem) CreateTeamCtx(ctx context.Context, param CreateTeamParam) uint32 {
+	attrs := []SpanAttribute{playerAttr("leader_id", param.LeaderID), membersAttr(param.MemberList)}
+	return ts.traceOp(ctx, OpCreateTeam, attrs, func(span Span) uint32 {
+		result := ts.CreateTeam(param)
+		if result == kOK {
+			span.SetAttributes(teamAttr(ts.lastTeamID))
+		}
+		return result
+	})
+}
+
+func (ts *TeamSystem) CreateRaidCtx(ctx context.Context, param CreateTeamParam) uint32 {
+	attrs := []SpanAttribute{playerAttr("leader_id", param.LeaderID), membersAttr(param.MemberList)}
+	return ts.traceOp(ctx, OpCreateRaid, attrs, func(span Span) uint32 {
+		result := ts.CreateRaid(param)
+		if result == kOK {
+			span.SetAttributes(teamAttr(ts.lastTeamID))
+		}
+		return result
+	})
+}
+
+func (ts *TeamSystem) JoinTeamCtx(ctx context.Context, teamID, guid uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpJoinTeam, attrs, func(Span) uint32 {
+		return ts.JoinTeam(teamID, guid)
+	})
+}
+
+func (ts *TeamSystem) JoinTeamByMemberListCtx(ctx context.Context, memberList GuidVector, teamID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), membersAttr(memberList)}
+	return ts.traceOp(ctx, OpJoinTeamByMemberList, attrs, func(Span) uint32 {
+		return ts.JoinTeamByMemberList(memberList, teamID)
+	})
+}
+
+func (ts *TeamSystem) InviteToTeamCtx(ctx context.Context, teamID, inviterID, inviteeID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", inviterID), playerAttr("id", inviteeID)}
+	return ts.traceOp(ctx, OpInviteToTeam, attrs, func(Span) uint32 {
+		return ts.InviteToTeam(teamID, inviterID, inviteeID)
+	})
+}
+
+func (ts *TeamSystem) LeaveTeamCtx(ctx context.Context, guid uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(ts.teamIDOf(guid)), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpLeaveTeam, attrs, func(Span) uint32 {
+		return ts.LeaveTeam(guid)
+	})
+}
+
+func (ts *TeamSystem) KickMemberCtx(ctx context.Context, teamID, currentLeaderID, beKickID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID), playerAttr("id", beKickID)}
+	return ts.traceOp(ctx, OpKickMember, attrs, func(Span) uint32 {
+		return ts.KickMember(teamID, currentLeaderID, beKickID)
+	})
+}
+
+func (ts *TeamSystem) KickMemberIfVersionCtx(ctx context.Context, teamID, currentLeaderID, beKickID, version uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID), playerAttr("id", beKickID), versionAttr(version)}
+	return ts.traceOp(ctx, OpKickMember, attrs, func(Span) uint32 {
+		return ts.KickMemberIfVersion(teamID, currentLeaderID, beKickID, version)
+	})
+}
+
+func (ts *TeamSystem) DisbandedCtx(ctx context.Context, teamID, currentLeaderID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID)}
+	return ts.traceOp(ctx, OpDisbanded, attrs, func(Span) uint32 {
+		return ts.Disbanded(teamID, currentLeaderID)
+	})
+}
+
+func (ts *TeamSystem) DisbandedTeamNoLeaderCtx(ctx context.Context, teamID uint64) uint32 {
+	return ts.traceOp(ctx, OpDisbandedTeamNoLeader, []SpanAttribute{teamAttr(teamID)}, func(Span) uint32 {
+		return ts.DisbandedTeamNoLeader(teamID)
+	})
+}
+
+func (ts *TeamSystem) AppointLeaderCtx(ctx context.Context, teamID, currentLeaderID, newLeaderID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID), playerAttr("id", newLeaderID)}
+	return ts.traceOp(ctx, OpAppointLeader, attrs, func(Span) uint32 {
+		return ts.AppointLeader(teamID, currentLeaderID, newLeaderID)
+	})
+}
+
+func (ts *TeamSystem) AppointLeaderIfVersionCtx(ctx context.Context, teamID, currentLeaderID, newLeaderID, version uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID), playerAttr("id", newLeaderID), versionAttr(version)}
+	return ts.traceOp(ctx, OpAppointLeader, attrs, func(Span) uint32 {
+		return ts.AppointLeaderIfVersion(teamID, currentLeaderID, newLeaderID, version)
+	})
+}
+
+func (ts *TeamSystem) ApplyToTeamCtx(ctx context.Context, teamID, guid uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpApplyToTeam, attrs, func(Span) uint32 {
+		return ts.ApplyToTeam(teamID, guid)
+	})
+}
+
+func (ts *TeamSystem) DelApplicantCtx(ctx context.Context, teamID, guid uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpDelApplicant, attrs, func(Span) uint32 {
+		return ts.DelApplicant(teamID, guid)
+	})
+}
+
+func (ts *TeamSystem) ClearApplyListCtx(ctx context.Context, teamID uint64) uint32 {
+	return ts.traceOp(ctx, OpClearApplyList, []SpanAttribute{teamAttr(teamID)}, func(Span) uint32 {
+		return ts.ClearApplyList(teamID)
+	})
+}
+
+func (ts *TeamSystem) WithdrawApplicationCtx(ctx context.Context, teamID, guid uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpWithdrawApplication, attrs, func(Span) uint32 {
+		return ts.WithdrawApplication(teamID, guid)
+	})
+}
+
+func (ts *TeamSystem) SetMemberRoleCtx(ctx context.Context, teamID, operatorID, guid uint64, role MemberRole) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", operatorID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpSetMemberRole, attrs, func(Span) uint32 {
+		return ts.SetMemberRole(teamID, operatorID, guid, role)
+	})
+}
+
+func (ts *TeamSystem) SetMemberRankCtx(ctx context.Context, teamID, operatorID, guid uint64, rank uint32) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", operatorID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpSetMemberRank, attrs, func(Span) uint32 {
+		return ts.SetMemberRank(teamID, operatorID, guid, rank)
+	})
+}
+
+func (ts *TeamSystem) SetMemberAttributeCtx(ctx context.Context, teamID, guid uint64, key, value string) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpSetMemberAttribute, attrs, func(Span) uint32 {
+		return ts.SetMemberAttribute(teamID, guid, key, value)
+	})
+}
+
+func (ts *TeamSystem) SetRaidAssistantCtx(ctx context.Context, teamID, currentLeaderID, guid uint64, assistant bool) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpSetRaidAssistant, attrs, func(Span) uint32 {
+		return ts.SetRaidAssistant(teamID, currentLeaderID, guid, assistant)
+	})
+}
+
+func (ts *TeamSystem) MoveRaidMemberCtx(ctx context.Context, teamID, operatorID, guid uint64, group int) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", operatorID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpMoveRaidMember, attrs, func(Span) uint32 {
+		return ts.MoveRaidMember(teamID, operatorID, guid, group)
+	})
+}
+
+func (ts *TeamSystem) SwapRaidMembersCtx(ctx context.Context, teamID, operatorID, first, second uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", operatorID), membersAttr(GuidVector{first, second})}
+	return ts.traceOp(ctx, OpSwapRaidMembers, attrs, func(Span) uint32 {
+		return ts.SwapRaidMembers(teamID, operatorID, first, second)
+	})
+}
+
+func (ts *TeamSystem) SetLootMethodCtx(ctx context.Context, teamID, currentLeaderID uint64, method LootMethod, masterLooterID uint64) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", currentLeaderID)}
+	return ts.traceOp(ctx, OpSetLootMethod, attrs, func(Span) uint32 {
+		return ts.SetLootMethod(teamID, currentLeaderID, method, masterLooterID)
+	})
+}
+
+// DistributeLootCtx returns the awards of the first call to retries of the same request
+func (ts *TeamSystem) DistributeLootCtx(ctx context.Context, teamID uint64, items []LootItem) ([]LootAward, uint32) {
+	return traceCall(ts, ctx, OpDistributeLoot, []SpanAttribute{teamAttr(teamID)}, func(Span) ([]LootAward, uint32) {
+		return ts.DistributeLoot(teamID, items)
+	})
+}
+
+func (ts *TeamSystem) EnterInstanceCtx(ctx context.Context, teamID, activityID, instanceID uint64, expiresAt time.Time) uint32 {
+	return ts.traceOp(ctx, OpEnterInstance, []SpanAttribute{teamAttr(teamID)}, func(Span) uint32 {
+		return ts.EnterInstance(teamID, activityID, instanceID, expiresAt)
+	})
+}
+
+func (ts *TeamSystem) LeaveInstanceCtx(ctx context.Context, teamID uint64) uint32 {
+	return ts.traceOp(ctx, OpLeaveInstance, []SpanAttribute{teamAttr(teamID)}, func(Span) uint32 {
+		return ts.LeaveInstance(teamID)
+	})
+}
+
+func (ts *TeamSystem) UpdateTeamSettingsCtx(ctx context.Context, teamID, editorID, version uint64, update func(*TeamSettings)) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", editorID)}
+	return ts.traceOp(ctx, OpUpdateTeamSettings, attrs, func(Span) uint32 {
+		return ts.UpdateTeamSettings(teamID, editorID, version, update)
+	})
+}
+
+func (ts *TeamSystem) SetAutoAcceptRulesCtx(ctx context.Context, teamID, editorID uint64, rules []AutoAcceptRule) uint32 {
+	attrs := []SpanAttribute{teamAttr(teamID), playerAttr("actor_id", editorID)}
+	return ts.traceOp(ctx, OpSetAutoAcceptRules, attrs, func(Span) uint32 {
+		return ts.SetAutoAcceptRules(teamID, editorID, rules)
+	})
+}
+
+// ScheduleTeamCtx returns the roster created by the first call to retries of the same request
+func (ts *TeamSystem) ScheduleTeamCtx(ctx context.Context, param CreateTeamParam, startAt time.Time) (uint64, uint32) {
+	attrs := []SpanAttribute{playerAttr("leader_id", param.LeaderID), membersAttr(param.MemberList)}
+	return traceCall(ts, ctx, OpScheduleTeam, attrs, func(span Span) (uint64, uint32) {
+		scheduleID, result := ts.ScheduleTeam(param, startAt)
+		if result == kOK {
+			span.SetAttributes(scheduleAttr(scheduleID))
+		}
+		return scheduleID, result
+	})
+}
+
+func (ts *TeamSystem) SignUpCtx(ctx context.Context, scheduleID, guid uint64) uint32 {
+	attrs := []SpanAttribute{scheduleAttr(scheduleID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpSignUp, attrs, func(Span) uint32 {
+		return ts.SignUp(scheduleID, guid)
+	})
+}
+
+func (ts *TeamSystem) CancelSignUpCtx(ctx context.Context, scheduleID, guid uint64) uint32 {
+	attrs := []SpanAttribute{scheduleAttr(scheduleID), playerAttr("id", guid)}
+	return ts.traceOp(ctx, OpCancelSignUp, attrs, func(Span) uint32 {
+		return ts.CancelSignUp(scheduleID, guid)
+	})
+}
+
+func (ts *TeamSystem) CancelScheduleCtx(ctx context.Context, scheduleID, leaderID uint64) uint32 {
+	attrs := []SpanAttribute{scheduleAttr(scheduleID), playerAttr("actor_id", leaderID)}
+	return ts.traceOp(ctx, OpCancelSchedule, attrs, func(Span) uint32 {
+		return ts.CancelSchedule(scheduleID, leaderID)
+	})
+}
+
+// ActivateScheduleCtx returns the report of the first call to retries of the same request
+func (ts *TeamSystem) ActivateScheduleCtx(ctx context.Context, scheduleID uint64) (ActivationReport, uint32) {
+	return traceCall(ts, ctx, OpActivateSchedule, []SpanAttribute{scheduleAttr(scheduleID)}, func(span Span) (ActivationReport, uint32) {
+		report, result := ts.ActivateSchedule(scheduleID)
+		if result == kOK {
+			span.SetAttributes(teamAttr(report.TeamID))
+		}
+		return report, result
+	})
+}
+
+// RecordedSpan is a span kept by SpanRecorder
+type RecordedSpan struct {
+	ID          uint64
+	ParentID    uint64 // Zero for root spans
+	Name        string
+	Attributes  map[string]any
+	OK          bool
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// SpanRecorder is an in-memory Tracer keeping every ended span, safe for concurrent use
+type SpanRecorder struct {
+	mu     sync.Mutex
+	lastID uint64
+	ended  []RecordedSpan
+}
+
+type spanRecorderKey struct{}
+
+type recorderSpan struct {
+	recorder *SpanRecorder
+	span     RecordedSpan
+}
+
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+func (r *SpanRecorder) Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	r.mu.Lock()
+	r.lastID++
+	id := r.lastID
+	r.mu.Unlock()
+
+	span := &recorderSpan{
+		recorder: r,
+		span: RecordedSpan{
+			ID:         id,
+			Name:       name,
+			Attributes: make(map[string]any),
+			OK:         true,
+			Start:      time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(spanRecorderKey{}).(*recorderSpan); ok {
+		span.span.ParentID = parent.span.ID
+	}
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanRecorderKey{}, span), span
+}
+
+// Spans returns the ended spans in the order they ended
+func (r *SpanRecorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan{}, r.ended...)
+}
+
+func (s *recorderSpan) SetAttributes(attrs ...SpanAttribute) {
+	for _, attr := range attrs {
+		s.span.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recorderSpan) SetStatus(ok bool, description string) {
+	s.span.OK = ok
+	s.span.Description = description
+}
+
+func (s *recorderSpan) End() {
+	s.span.End = time.Now()
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.ended = append(s.recorder.ended, s.span)
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTracedOperations(t *testing.T) {
+	recorder := NewSpanRecorder()
+	ts := NewTeamSystem(WithTracer(recorder))
+
+	ctx, parent := recorder.Start(context.Background(), "gateway.request")
+	if got := ts.CreateTeamCtx(ctx, NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("CreateTeamCtx() = %v, want %v", got, kOK)
+	}
+	teamID := ts.LastTeamID()
+	if got := ts.JoinTeamByMemberListCtx(ctx, GuidVector{101, 102}, teamID); got != kOK {
+		t.Errorf("JoinTeamByMemberListCtx() = %v, want %v", got, kOK)
+	}
+	if got := ts.KickMemberCtx(ctx, teamID, 101, 102); got != kTeamKickNotLeader {
+		t.Errorf("KickMemberCtx() = %v, want %v", got, kTeamKickNotLeader)
+	}
+	parent.End()
+
+	spans := recorder.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("Spans() = %v spans, want %v", len(spans), 4)
+	}
+	root := spans[3]
+	for _, span := range spans[:3] {
+		if span.ParentID != root.ID {
+			t.Errorf("span %v ParentID = %v, want %v", span.Name, span.ParentID, root.ID)
+		}
+	}
+
+	create := spans[0]
+	if create.Name != "team.CreateTeam" || create.Attributes["team.id"] != teamID || !create.OK {
+		t.Errorf("create span = %+v, want team.CreateTeam for team %v", create, teamID)
+	}
+	join := spans[1]
+	if got := join.Attributes["player.members"]; !reflect.DeepEqual(got, GuidVector{101, 102}) {
+		t.Errorf("join span members = %v, want %v", got, GuidVector{101, 102})
+	}
+	kick := spans[2]
+	if kick.OK || kick.Attributes["team.result"] != uint64(kTeamKickNotLeader) || kick.Attributes["player.actor_id"] != uint64(101) {
+		t.Errorf("kick span = %+v, want failed with result %v", kick, kTeamKickNotLeader)
+	}
+}
+
+func TestTracedOperationCanceled(t *testing.T) {
+	recorder := NewSpanRecorder()
+	ts := NewTeamSystem(WithTracer(recorder))
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := ts.JoinTeamByMemberListCtx(ctx, GuidVector{101, 102}, ts.LastTeamID()); got != kTeamContextDone {
+		t.Errorf("JoinTeamByMemberListCtx() = %v, want %v", got, kTeamContextDone)
+	}
+	if ts.HasTeam(101) || ts.HasTeam(102) {
+		t.Errorf("Expected canceled call not to join any member")
+	}
+
+	spans := recorder.Spans()
+	if len(spans) != 1 || spans[0].OK || spans[0].Description != context.Canceled.Error() {
+		t.Errorf("Spans() = %+v, want one canceled span", spans)
+	}
+
+	// Context-aware calls work without a tracer
+	ts = NewTeamSystem()
+	if got := ts.CreateTeamCtx(context.Background(), NewCreateTeamParam(100, []uint64{100})); got != kOK {
+		t.Errorf("CreateTeamCtx() = %v, want %v", got, kOK)
+	}
+}
@@ -0,0 +1,45 @@
+package pkg
+
+// TeamVersion returns the version of the team, it changes whenever the team does
+func (ts *TeamSystem) TeamVersion(teamID uint64) (uint64, bool) {
+	if team, ok := ts.teams[teamID]; ok {
+		return team.Version, true
+	}
+	return 0, false
+}
+
+// KickMemberIfVersion kicks beKickID only if the team is still at version,
+// otherwise kTeamVersionConflict is returned and nothing changes
+func (ts *TeamSystem) KickMemberIfVersion(teamID, currentLeaderID, beKickID, version uint64) uint32 {
+	call := ts.beginOp(OpKickMember, teamID, currentLeaderID, beKickID)
+	call.version = &version
+	if err := ts.checkVersion(teamID, version); err != kOK {
+		return ts.endOp(call, err)
+	}
+	if err := ts.checkRateLimit(OpKickMember, teamID, currentLeaderID); err != kOK {
+		return ts.endOp(call, err)
+	}
+	return ts.endOp(call, ts.kickMember(teamID, currentLeaderID, beKickID))
+}
+
+// AppointLeaderIfVersion hands leadership to newLeaderID only if the team is still at version,
+// otherwise kTeamVersionConflict is returned and nothing changes
+func (ts *TeamSystem) AppointLeaderIfVersion(teamID, currentLeaderID, newLeaderID, version uint64) uint32 {
+	call := ts.beginOp(OpAppointLeader, teamID, currentLeaderID, newLeaderID)
+	call.version = &version
+	if err := ts.checkVersion(teamID, version); err != kOK {
+		return ts.endOp(call, err)
+	}
+	return ts.endOp(call, ts.appointLeader(teamID, currentLeaderID, newLeaderID))
+}
+
+func (ts *TeamSystem) checkVersion(teamID, version uint64) uint32 {
+	team, ok := ts.teams[teamID]
+	if !ok {
+		return kTeamHasNotTeamId
+	}
+	if team.Version != version {
+		return kTeamVersionConflict
+	}
+	return kOK
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTeamVersionBumps(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101}))
+	teamID := ts.LastTeamID()
+
+	last, _ := ts.TeamVersion(teamID)
+	if last == 0 {
+		t.Fatalf("TeamVersion() of a new team = 0, want non-zero")
+	}
+	for _, step := range []struct {
+		name string
+		run  func() uint32
+	}{
+		{"ApplyToTeam", func() uint32 { return ts.ApplyToTeam(teamID, 200) }},
+		{"JoinTeam", func() uint32 { return ts.JoinTeam(teamID, 200) }},
+		{"SetMemberRole", func() uint32 { return ts.SetMemberRole(teamID, 101, 101, RoleTank) }},
+		{"EnterInstance", func() uint32 { return ts.EnterInstance(teamID, 7, 70, time.Now().Add(time.Hour)) }},
+		{"LeaveInstance", func() uint32 { return ts.LeaveInstance(teamID) }},
+		{"AppointLeader", func() uint32 { return ts.AppointLeader(teamID, 100, 101) }},
+		{"KickMember", func() uint32 { return ts.KickMember(teamID, 101, 200) }},
+		{"LeaveTeam", func() uint32 { return ts.LeaveTeam(100) }},
+	} {
+		if got := step.run(); got != kOK {
+			t.Fatalf("%s() = %v, want %v", step.name, got, kOK)
+		}
+		version, _ := ts.TeamVersion(teamID)
+		if version <= last {
+			t.Errorf("TeamVersion() after %s = %v, want above %v", step.name, version, last)
+		}
+		last = version
+	}
+
+	// Failed calls leave the version alone
+	ts.KickMember(teamID, 100, 101)
+	if version, _ := ts.TeamVersion(teamID); version != last {
+		t.Errorf("TeamVersion() after failed KickMember = %v, want %v", version, last)
+	}
+	if snapshot, _ := ts.TeamSnapshot(teamID); snapshot.Version != last {
+		t.Errorf("TeamSnapshot().Version = %v, want %v", snapshot.Version, last)
+	}
+	if _, ok := ts.TeamVersion(teamID + 1); ok {
+		t.Errorf("TeamVersion() of a missing team ok = true, want false")
+	}
+}
+
+func TestConditionalMutators(t *testing.T) {
+	ts := NewTeamSystem()
+	ts.CreateTeam(NewCreateTeamParam(100, []uint64{100, 101, 102}))
+	teamID := ts.LastTeamID()
+	rendered, _ := ts.TeamVersion(teamID)
+
+	// Player 101 leaves after the leader rendered the team
+	ts.LeaveTeam(101)
+	if got := ts.KickMemberIfVersion(teamID, 100, 101, rendered); got != kTeamVersionConflict {
+		t.Errorf("KickMemberIfVersion() with stale version = %v, want %v", got, kTeamVersionConflict)
+	}
+	if got := ts.AppointLeaderIfVersion(teamID, 100, 102, rendered); got != kTeamVersionConflict {
+		t.Errorf("AppointLeaderIfVersion() with stale version = %v, want %v", got, kTeamVersionConflict)
+	}
+	if got := ts.GetLeaderIDByTeamID(teamID); got != 100 {
+		t.Errorf("GetLeaderIDByTeamID() = %v, want %v", got, 100)
+	}
+
+	current, _ := ts.TeamVersion(teamID)
+	if got := ts.AppointLeaderIfVersion(teamID, 100, 102, current); got != kOK {
+		t.Errorf("AppointLeaderIfVersion() = %v, want %v", got, kOK)
+	}
+	current, _ = ts.TeamVersion(teamID)
+	if got := ts.KickMemberIfVersion(teamID, 102, 100, current); got != kOK {
+		t.Errorf("KickMemberIfVersion() = %v, want %v", got, kOK)
+	}
+	if got := ts.KickMemberIfVersion(teamID+1, 102, 100, current); got != kTeamHasNotTeamId {
+		t.Errorf("KickMemberIfVersion() on a missing team = %v, want %v", got, kTeamHasNotTeamId)
+	}
+}
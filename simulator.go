@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"team/pkg"
+)
+
+// command is one simulator command, args holds the usage of its arguments
+type command struct {
+	args string
+	help string
+	run  func(s *simulator, args []string) (uint32, error)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"create": {"LEADER MEMBERS [SIZE]", "create a team, MEMBERS is a comma separated list", cmdCreate},
+		"raid":   {"LEADER MEMBERS", "create a raid", cmdRaid},
+		"apply": {"TEAM PLAYER", "apply to a team", func(s *simulator, a []string) (uint32, error) {
+			return s.call2(a, s.ts.ApplyToTeam)
+		}},
+		"join": {"TEAM PLAYER", "join a team", func(s *simulator, a []string) (uint32, error) {
+			return s.call2(a, s.ts.JoinTeam)
+		}},
+		"joinlist": {"TEAM MEMBERS", "join a team with a group of players", cmdJoinList},
+		"invite": {"TEAM INVITER PLAYER", "invite a player into a team", func(s *simulator, a []string) (uint32, error) {
+			return s.call3(a, s.ts.InviteToTeam)
+		}},
+		"leave": {"PLAYER", "leave the team of a player", func(s *simulator, a []string) (uint32, error) {
+			ids, err := parseIDs(a, 1)
+			if err != nil {
+				return 0, err
+			}
+			return s.ts.LeaveTeam(ids[0]), nil
+		}},
+		"kick": {"TEAM LEADER PLAYER", "kick a member", func(s *simulator, a []string) (uint32, error) {
+			return s.call3(a, s.ts.KickMember)
+		}},
+		"appoint": {"TEAM LEADER PLAYER", "hand leadership to a member", func(s *simulator, a []string) (uint32, error) {
+			return s.call3(a, s.ts.AppointLeader)
+		}},
+		"disband": {"TEAM LEADER", "disband a team", func(s *simulator, a []string) (uint32, error) {
+			return s.call2(a, s.ts.Disbanded)
+		}},
+		"reject": {"TEAM PLAYER", "remove an applicant", func(s *simulator, a []string) (uint32, error) {
+			return s.call2(a, s.ts.DelApplicant)
+		}},
+		"withdraw": {"TEAM PLAYER", "withdraw an application", func(s *simulator, a []string) (uint32, error) {
+			return s.call2(a, s.ts.WithdrawApplication)
+		}},
+		"clear": {"TEAM", "clear the applicants of a team", func(s *simulator, a []string) (uint32, error) {
+			ids, err := parseIDs(a, 1)
+			if err != nil {
+				return 0, err
+			}
+			return s.ts.ClearApplyList(ids[0]), nil
+		}},
+	}
+}
+
+// simulator runs text commands against a TeamSystem
+type simulator struct {
+	ts        *pkg.TeamSystem
+	out       io.Writer
+	showState bool
+}
+
+func newSimulator(ts *pkg.TeamSystem, out io.Writer) *simulator {
+	return &simulator{ts: ts, out: out, showState: true}
+}
+
+// exec runs one line and reports whether the simulator should stop. Blank lines and
+// lines starting with # are ignored.
+func (s *simulator) exec(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return false, nil
+	}
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		s.help()
+		return false, nil
+	case "show":
+		return false, s.show(args)
+	case "applications":
+		ids, err := parseIDs(args, 1)
+		if err != nil {
+			return false, fmt.Errorf("%w\nusage: applications PLAYER", err)
+		}
+		fmt.Fprintf(s.out, "player %d applied to %s\n", ids[0], formatIDs(s.ts.ListMyApplications(ids[0])))
+		return false, nil
+	case "dump":
+		if len(args) != 1 {
+			return false, errors.New("usage: dump FILE")
+		}
+		return false, s.dump(args[0])
+	case "load":
+		if len(args) != 1 {
+			return false, errors.New("usage: load FILE")
+		}
+		return false, s.load(args[0])
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		return false, fmt.Errorf("unknown command %q", name)
+	}
+	result, err := cmd.run(s, args)
+	if err != nil {
+		return false, fmt.Errorf("%w\nusage: %s %s", err, name, cmd.args)
+	}
+	if result == 0 {
+		fmt.Fprintf(s.out, "%s: ok\n", name)
+	} else {
+		fmt.Fprintf(s.out, "%s: failed with code %d\n", name, result)
+	}
+	if s.showState {
+		s.printTeams()
+	}
+	return false, nil
+}
+
+func (s *simulator) help() {
+	tw := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for _, name := range []string{"create", "raid", "apply", "join", "joinlist", "invite", "leave", "kick", "appoint", "disband", "reject", "withdraw", "clear"} {
+		fmt.Fprintf(tw, "%s %s\t%s\n", name, commands[name].args, commands[name].help)
+	}
+	fmt.Fprintln(tw, "show [TEAM]\tprint all teams, or the members of one team")
+	fmt.Fprintln(tw, "applications PLAYER\tlist the teams a player applied to")
+	fmt.Fprintln(tw, "dump FILE\twrite a snapshot of the state")
+	fmt.Fprintln(tw, "load FILE\treplace the state with a snapshot")
+	fmt.Fprintln(tw, "quit\tleave the simulator")
+	tw.Flush()
+}
+
+func (s *simulator) show(args []string) error {
+	if len(args) == 0 {
+		s.printTeams()
+		return nil
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+	team, ok := s.ts.TeamSnapshot(ids[0])
+	if !ok {
+		return fmt.Errorf("team %d not found", ids[0])
+	}
+	tw := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "team %d, leader %d, %d/%d members, applicants %s\n",
+		team.ID, team.LeaderID, len(team.MemberList), team.TeamTypeSize, formatIDs(team.Applicants))
+	fmt.Fprintln(tw, "MEMBER\tJOIN METHOD\tROLE\tRANK")
+	for _, member := range team.MemberList {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\n", member.Guid, member.JoinMethod, member.Role, member.Rank)
+	}
+	return tw.Flush()
+}
+
+func (s *simulator) printTeams() {
+	snapshot := s.ts.Snapshot()
+	if len(snapshot.Teams) == 0 {
+		fmt.Fprintln(s.out, "  no teams")
+		return
+	}
+	tw := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for _, team := range snapshot.Teams {
+		fmt.Fprintf(tw, "  team %d\tleader %d\tmembers %s\tapplicants %s\n",
+			team.ID, team.LeaderID, formatIDs(team.MemberList.Guids()), formatIDs(team.Applicants))
+	}
+	tw.Flush()
+}
+
+func (s *simulator) dump(path string) error {
+	data, err := json.MarshalIndent(s.ts.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *simulator) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var snapshot pkg.SystemSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.ts = pkg.RestoreTeamSystem(snapshot)
+	if s.showState {
+		s.printTeams()
+	}
+	return nil
+}
+
+func (s *simulator) call2(args []string, op func(a, b uint64) uint32) (uint32, error) {
+	ids, err := parseIDs(args, 2)
+	if err != nil {
+		return 0, err
+	}
+	return op(ids[0], ids[1]), nil
+}
+
+func (s *simulator) call3(args []string, op func(a, b, c uint64) uint32) (uint32, error) {
+	ids, err := parseIDs(args, 3)
+	if err != nil {
+		return 0, err
+	}
+	return op(ids[0], ids[1], ids[2]), nil
+}
+
+func cmdCreate(s *simulator, args []string) (uint32, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return 0, errors.New("expected 2 or 3 arguments")
+	}
+	ids, err := parseIDs(args[:1], 1)
+	if err != nil {
+		return 0, err
+	}
+	members, err := parseList(args[1])
+	if err != nil {
+		return 0, err
+	}
+	param := pkg.NewCreateTeamParam(ids[0], members)
+	if len(args) == 3 {
+		if param.TeamTypeSize, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid size %q", args[2])
+		}
+	}
+	return s.ts.CreateTeam(param), nil
+}
+
+func cmdRaid(s *simulator, args []string) (uint32, error) {
+	if len(args) != 2 {
+		return 0, errors.New("expected 2 arguments")
+	}
+	ids, err := parseIDs(args[:1], 1)
+	if err != nil {
+		return 0, err
+	}
+	members, err := parseList(args[1])
+	if err != nil {
+		return 0, err
+	}
+	return s.ts.CreateRaid(pkg.NewCreateTeamParam(ids[0], members)), nil
+}
+
+func cmdJoinList(s *simulator, args []string) (uint32, error) {
+	if len(args) != 2 {
+		return 0, errors.New("expected 2 arguments")
+	}
+	ids, err := parseIDs(args[:1], 1)
+	if err != nil {
+		return 0, err
+	}
+	members, err := parseList(args[1])
+	if err != nil {
+		return 0, err
+	}
+	return s.ts.JoinTeamByMemberList(members, ids[0]), nil
+}
+
+func parseIDs(args []string, count int) ([]uint64, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("expected %d arguments", count)
+	}
+	ids := make([]uint64, 0, count)
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseList parses a comma separated list of ids, "-" is the empty list
+func parseList(arg string) (pkg.GuidVector, error) {
+	list := make(pkg.GuidVector, 0)
+	if arg == "-" {
+		return list, nil
+	}
+	for _, part := range strings.Split(arg, ",") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q in list", part)
+		}
+		list = append(list, id)
+	}
+	return list, nil
+}
+
+func formatIDs(ids pkg.GuidVector) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
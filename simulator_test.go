@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"team/pkg"
+)
+
+func TestSimulatorScript(t *testing.T) {
+	var out bytes.Buffer
+	sim := newSimulator(pkg.NewTeamSystem(), &out)
+	sim.showState = false
+	dump := filepath.Join(t.TempDir(), "state.json")
+
+	for _, tt := range []struct {
+		line    string
+		want    string
+		wantErr string
+	}{
+		{line: "# comment"},
+		{line: ""},
+		{line: "create 1 2,3 5", want: "create: ok\n"},
+		{line: "apply 1 9", want: "apply: ok\n"},
+		{line: "apply 1 2", want: "apply: failed with code "},
+		{line: "apply 1", wantErr: "expected 2 arguments\nusage: apply TEAM PLAYER"},
+		{line: "create 1 2,x", wantErr: `invalid id "x" in list`},
+		{line: "teleport 1", wantErr: `unknown command "teleport"`},
+		{line: "applications 9", want: "player 9 applied to 1\n"},
+		{line: "dump " + dump},
+	} {
+		out.Reset()
+		done, err := sim.exec(tt.line)
+		if done {
+			t.Errorf("exec(%q) done = true, want false", tt.line)
+		}
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("exec(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("exec(%q) error = %v, want nil", tt.line, err)
+		}
+		if !strings.HasPrefix(out.String(), tt.want) || (tt.want == "" && out.Len() != 0) {
+			t.Errorf("exec(%q) output = %q, want %q", tt.line, out.String(), tt.want)
+		}
+	}
+
+	team, ok := sim.ts.TeamSnapshot(1)
+	if !ok || team.LeaderID != 1 || team.TeamTypeSize != 5 ||
+		!reflect.DeepEqual(team.MemberList.Guids(), pkg.GuidVector{2, 3}) || !reflect.DeepEqual(team.Applicants, pkg.GuidVector{9}) {
+		t.Errorf("TeamSnapshot(1) = %+v, want leader 1, members 2,3 of 5 and applicant 9", team)
+	}
+
+	want, _ := json.Marshal(sim.ts.Snapshot())
+	if _, err := sim.exec("disband 1 1"); err != nil {
+		t.Fatalf("exec(disband) error = %v", err)
+	}
+	if _, err := sim.exec("load " + dump); err != nil {
+		t.Fatalf("exec(load) error = %v", err)
+	}
+	if got, _ := json.Marshal(sim.ts.Snapshot()); !bytes.Equal(got, want) {
+		t.Errorf("Snapshot() after load = %s, want %s", got, want)
+	}
+	if got := sim.ts.ListMyApplications(9); !reflect.DeepEqual(got, pkg.GuidVector{1}) {
+		t.Errorf("ListMyApplications() after load = %v, want %v", got, pkg.GuidVector{1})
+	}
+
+	out.Reset()
+	sim.showState = true
+	sim.exec("leave 3")
+	if got := out.String(); got != "leave: ok\n  team 1  leader 1  members 2  applicants 9\n" {
+		t.Errorf("exec(leave) output = %q", got)
+	}
+	if done, _ := sim.exec("quit"); !done {
+		t.Errorf("exec(quit) done = false, want true")
+	}
+}